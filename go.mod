@@ -1,5 +1,3 @@
 module github.com/aviadl/thirdPartyLicenseCollector
 
-go 1.12
-
-require github.com/ryanuber/go-license v0.0.0-20180405065157-c69f41c2c8d6
+go 1.18
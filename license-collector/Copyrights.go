@@ -0,0 +1,209 @@
+package licensecollector
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// copyright is a copyright statement of a package
+type copyright struct {
+	// Years are the normalized copyright years, e.g. "2015-2017, 2019", empty when unknown
+	Years string `json:"years,omitempty"`
+	// Holder is the copyright holder, without contact details
+	Holder string `json:"holder"`
+}
+
+// String returns the statement as "© 2019 Google LLC"
+func (c copyright) String() string {
+	if len(c.Years) == 0 {
+		return "© " + c.Holder
+	}
+	return "© " + c.Years + " " + c.Holder
+}
+
+// copyrightFilePrefixes are the prefixes of the files holding copyright statements besides license files
+var copyrightFilePrefixes = []string{"notice", "copyright", "authors"}
+
+var (
+	// copyrightStatement matches a copyright statement: the copyright sign or word followed by
+	// a sign or years, after comment markers. "Copyright notice" and the like are license terms.
+	copyrightStatement = regexp.MustCompile(`(?i)^[\s#*/;!-]*(?:copyright\s*(?:\(c\)|©)?|©|\(c\))\s*((?:\d{4}(?:\s*[-–—]\s*(?:\d{4}|present))?[\s,]*)*)(.*)$`)
+	// copyrightYears matches a year or a range of years
+	copyrightYears = regexp.MustCompile(`(?i)(\d{4})(?:\s*[-–—]\s*(\d{4}|present))?`)
+	// contactDetails matches e-mail addresses and urls of a holder
+	contactDetails = regexp.MustCompile(`<[^>]*>|\([^)]*(?:@|://)[^)]*\)|\S+@\S+|https?://\S+`)
+	// allRightsReserved matches the suffix of a statement
+	allRightsReserved = regexp.MustCompile(`(?i)[\s,;]*all rights reserved.*$`)
+	// abbreviatedName matches a holder ending with an abbreviation, e.g. "Widgets Inc." or "J. R. R."
+	abbreviatedName = regexp.MustCompile(`(?i)(?:^|[\s,])(?:inc|ltd|co|corp|l\.l\.c|gmbh|s\.a|b\.v|n\.v|pty|plc|jr|sr|[a-z])\.$`)
+	// templateHolder matches the placeholders of license templates, e.g. "<copyright holders>"
+	templateHolder = regexp.MustCompile(`(?i)^(?:\[|<|\{)|^(?:the )?copyright (?:holders?|owners?)\b|^\[?(?:yyyy|year)\b|^name of`)
+)
+
+// isCopyrightFile checks if a file may hold copyright statements: a license file, NOTICE,
+// COPYRIGHT or AUTHORS, case insensitive. Sources such as notice.go are not text files.
+func isCopyrightFile(name string) bool {
+	if isLicenseFile(name) {
+		return true
+	}
+	for _, prefix := range copyrightFilePrefixes {
+		if len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+			return isTextFileName(name)
+		}
+	}
+	return false
+}
+
+// normalizeCopyrightYears merges years and ranges into sorted ranges, "2015, 2016, 2017" is "2015-2017".
+// The earliest open range, e.g. "2015-present", covers the later years.
+func normalizeCopyrightYears(s string) string {
+	years := map[int]struct{}{}
+	present := 0
+	for _, m := range copyrightYears.FindAllStringSubmatch(s, -1) {
+		from, _ := strconv.Atoi(m[1])
+		to := from
+		if strings.EqualFold(m[2], "present") {
+			if present == 0 || from < present {
+				present = from
+			}
+		} else if len(m[2]) > 0 {
+			to, _ = strconv.Atoi(m[2])
+		}
+		if to < from || to-from > 100 {
+			to = from
+		}
+		for y := from; y <= to; y++ {
+			years[y] = struct{}{}
+		}
+	}
+	var sorted []int
+	for y := range years {
+		if present == 0 || y <= present {
+			sorted = append(sorted, y)
+		}
+	}
+	sort.Ints(sorted)
+	var ranges []string
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] == sorted[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, strconv.Itoa(sorted[i]))
+		} else {
+			ranges = append(ranges, strconv.Itoa(sorted[i])+"-"+strconv.Itoa(sorted[j]))
+		}
+		i = j + 1
+	}
+	// the open range ends the list
+	if present > 0 {
+		last := ranges[len(ranges)-1]
+		ranges[len(ranges)-1] = strings.SplitN(last, "-", 2)[0] + "-present"
+	}
+	return strings.Join(ranges, ", ")
+}
+
+// normalizeCopyrightHolder drops contact details, "all rights reserved" and the punctuation around the holder
+func normalizeCopyrightHolder(s string) string {
+	s = allRightsReserved.ReplaceAllString(s, "")
+	s = contactDetails.ReplaceAllString(s, "")
+	s = strings.TrimPrefix(strings.TrimSpace(s), "by ")
+	s = strings.Join(strings.Fields(s), " ")
+	s = strings.Trim(s, " ,;:-*/")
+	// the period of an abbreviation is part of the name, the one ending a sentence is not
+	for strings.HasSuffix(s, ".") && !abbreviatedName.MatchString(s) {
+		s = strings.TrimRight(strings.TrimSuffix(s, "."), " ,;:-*/")
+	}
+	return s
+}
+
+// parseCopyrights extracts the copyright statements of a text
+func parseCopyrights(text string) []copyright {
+	var res []copyright
+	for _, line := range strings.Split(text, "\n") {
+		m := copyrightStatement.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		c := copyright{Years: normalizeCopyrightYears(m[1]), Holder: normalizeCopyrightHolder(m[2])}
+		// without years, only "©" or "Copyright (c)" make a statement: "(c)" alone is a list item
+		hasSign := strings.Contains(line, "©") || (strings.Contains(strings.ToLower(line), "copyright") && strings.Contains(strings.ToLower(line), "(c)"))
+		if len(c.Holder) == 0 || templateHolder.MatchString(c.Holder) || (len(c.Years) == 0 && !hasSign) {
+			continue
+		}
+		res = append(res, c)
+	}
+	return res
+}
+
+var (
+	licenseListCopyrightsOnce sync.Once
+	licenseListCopyrights     map[string]struct{}
+)
+
+// isLicenseListCopyright checks if a statement is part of a license text of the SPDX license
+// list, e.g. the copyright of the Free Software Foundation on the GPL
+func isLicenseListCopyright(c copyright) bool {
+	licenseListCopyrightsOnce.Do(func() {
+		licenseListCopyrights = map[string]struct{}{}
+		for _, l := range spdxLicenses {
+			for _, statement := range parseCopyrights(l.Text) {
+				licenseListCopyrights[strings.ToLower(statement.String())] = struct{}{}
+			}
+		}
+	})
+	_, ok := licenseListCopyrights[strings.ToLower(c.String())]
+	return ok
+}
+
+// mergeCopyrights adds statements to a list, the years of a holder are merged
+func mergeCopyrights(list []copyright, statements ...copyright) []copyright {
+	for _, c := range statements {
+		if isLicenseListCopyright(c) {
+			continue
+		}
+		merged := false
+		for i, existing := range list {
+			if strings.EqualFold(existing.Holder, c.Holder) {
+				list[i].Years = normalizeCopyrightYears(existing.Years + ", " + c.Years)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// readCopyrights extracts the copyright statements of the license, NOTICE, COPYRIGHT and
+// AUTHORS files of a directory. The package author is a holder when no statement names it.
+func readCopyrights(fsys sourceFS, dir string, author string) []copyright {
+	var res []copyright
+	entries, _ := fsys.ReadDir(dir)
+	for _, e := range entries {
+		if e.IsDir || !isCopyrightFile(e.Name) {
+			continue
+		}
+		text, err := fsys.ReadFile(path.Join(dir, e.Name))
+		if err != nil {
+			continue
+		}
+		res = mergeCopyrights(res, parseCopyrights(string(text))...)
+	}
+	if author = normalizeCopyrightHolder(author); len(author) > 0 {
+		for _, c := range res {
+			if strings.Contains(strings.ToLower(c.Holder), strings.ToLower(author)) {
+				return res
+			}
+		}
+		res = append(res, copyright{Holder: author})
+	}
+	return res
+}
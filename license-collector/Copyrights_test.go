@@ -0,0 +1,122 @@
+package licensecollector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsCopyrightFile(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"LICENSE", true},
+		{"NOTICE", true},
+		{"NOTICE.txt", true},
+		{"COPYRIGHT", true},
+		{"AUTHORS.md", true},
+		{"notice.go", false},
+		{"copyright_test.go", false},
+		{"license_windows.go", false},
+		{"authors.json", false},
+		{"README.md", false},
+	}
+	for _, tt := range tests {
+		if got := isCopyrightFile(tt.name); got != tt.want {
+			t.Errorf("isCopyrightFile(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeCopyrightYears(t *testing.T) {
+	tests := []struct{ years, want string }{
+		{"2019", "2019"},
+		{"2015, 2016, 2017", "2015-2017"},
+		{"2017, 2015-2016, 2019", "2015-2017, 2019"},
+		{"2015 - 2013", "2015"},
+		{"2015-present", "2015-present"},
+		{"2010, 2015-present", "2010, 2015-present"},
+		{"2015-present, 2019", "2015-present"},
+		{"2019, 2015-present", "2015-present"},
+		{"2012-2014, 2013-present", "2012-present"},
+		{"2018-present, 2015-present", "2015-present"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeCopyrightYears(tt.years); got != tt.want {
+			t.Errorf("normalizeCopyrightYears(%q) = %q, want %q", tt.years, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeCopyrightHolder(t *testing.T) {
+	tests := []struct{ holder, want string }{
+		{"Google LLC. All rights reserved.", "Google LLC"},
+		{"by Jane Doe <jane@example.com>", "Jane Doe"},
+		{"Acme (https://acme.example.com), ", "Acme"},
+		{"  The   Go  Authors.", "The Go Authors"},
+		{"Widgets Inc.", "Widgets Inc."},
+		{"Widgets Ltd., all rights reserved", "Widgets Ltd."},
+		{"Acme Co. All rights reserved.", "Acme Co."},
+		{"Jane Q.", "Jane Q."},
+	}
+	for _, tt := range tests {
+		if got := normalizeCopyrightHolder(tt.holder); got != tt.want {
+			t.Errorf("normalizeCopyrightHolder(%q) = %q, want %q", tt.holder, got, tt.want)
+		}
+	}
+}
+
+func TestParseCopyrights(t *testing.T) {
+	text := `Copyright (c) 2015, 2016 Google LLC. All rights reserved.
+// Copyright 2019-present Jane Doe <jane@example.com>
+© Acme
+(c) 2020 ListItem, without a sign is fine with years
+(c) not a statement
+Copyright <year> <copyright holders>
+The above copyright notice shall be included.
+`
+	want := []copyright{
+		{Years: "2015-2016", Holder: "Google LLC"},
+		{Years: "2019-present", Holder: "Jane Doe"},
+		{Holder: "Acme"},
+		{Years: "2020", Holder: "ListItem, without a sign is fine with years"},
+	}
+	if got := parseCopyrights(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCopyrights() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMergeCopyrights(t *testing.T) {
+	list := []copyright{{Years: "2015", Holder: "Google LLC"}}
+	// the copyright of the GPL text is not a copyright of the package
+	gpl := parseCopyrights(spdxLicenses["GPL-2.0-only"].Text)
+	if len(gpl) == 0 {
+		t.Fatal("no copyright statement in the GPL-2.0 text")
+	}
+	got := mergeCopyrights(list, copyright{Years: "2016", Holder: "google llc"}, copyright{Years: "2019", Holder: "Acme"}, gpl[0])
+	want := []copyright{{Years: "2015-2016", Holder: "Google LLC"}, {Years: "2019", Holder: "Acme"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeCopyrights() = %+v, want %+v", got, want)
+	}
+}
+
+func TestReadCopyrights(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"LICENSE":            "MIT License\n\nCopyright (c) 2019 Acme\n",
+		"NOTICE":             "Copyright 2020 Acme\nCopyright 2018 Widgets Inc.\n",
+		"notice.go":          "// Copyright 2019 Evil Corp\npackage notice\n",
+		"license_windows.go": "// Copyright 2019 Evil Corp\npackage license\n",
+	}
+	writeFiles(t, dir, files)
+	want := []copyright{{Years: "2019-2020", Holder: "Acme"}, {Years: "2018", Holder: "Widgets Inc."}, {Holder: "Jane Doe"}}
+	if got := readCopyrights(dirFS(dir), "", "Jane Doe <jane@example.com>"); !reflect.DeepEqual(got, want) {
+		t.Errorf("readCopyrights() = %+v, want %+v", got, want)
+	}
+	// the author is not repeated when a statement names it
+	want = want[:2]
+	if got := readCopyrights(dirFS(dir), "", "Acme"); !reflect.DeepEqual(got, want) {
+		t.Errorf("readCopyrights() = %+v, want %+v", got, want)
+	}
+}
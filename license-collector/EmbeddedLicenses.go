@@ -0,0 +1,72 @@
+package licensecollector
+
+import (
+	"log"
+	"path"
+	"strings"
+)
+
+// embeddedSkipDirs are the directories never scanned for embedded components: tests, fixtures
+// and examples are not shipped, node_modules holds packages collected on their own
+var embeddedSkipDirs = []string{"testdata", "test", "fixtures", "examples", nodeModules}
+
+// isEmbeddedScanDir checks if a subdirectory of a package may hold an embedded component
+func isEmbeddedScanDir(name string) bool {
+	return !InStringSlice(embeddedSkipDirs, name) && !strings.HasPrefix(name, ".")
+}
+
+// addEmbeddedLicenses reports every distinct license file in the subdirectories of a package,
+// e.g. a library copied into third_party/ or vendor/, as an entry of the package. License files
+// identical to one already reported for the package are skipped, and so are the directories
+// of other packages nested in it.
+func (c *licenseCollection) addEmbeddedLicenses(fsys sourceFS, dep dependency) {
+	if len(dep.LicenseDirs) == 0 {
+		return
+	}
+	root := dep.LicenseDirs[0]
+	seen := map[string]struct{}{}
+	if licenses, err := licensesFromFS(fsys, root); err == nil {
+		for _, l := range licenses {
+			seen[licenseTextHash(l.Text)] = struct{}{}
+		}
+	}
+	queue := []string{root}
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+		entries, err := fsys.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			sub := path.Join(dir, e.Name)
+			if !e.IsDir || !isEmbeddedScanDir(e.Name) || InStringSlice(dep.Nested, sub) {
+				continue
+			}
+			queue = append(queue, sub)
+			licenses, err := licensesFromFS(fsys, sub)
+			if err != nil {
+				continue
+			}
+			embedded := strings.TrimPrefix(sub, root+"/")
+			for _, l := range licenses {
+				hash := licenseTextHash(l.Text)
+				if _, ok := seen[hash]; ok {
+					continue
+				}
+				seen[hash] = struct{}{}
+				log.Printf("Found embedded license %s of %s: %s\n", l.ID, dep.Name, l.File)
+				entry := dep.entry(sub, l.ID, "", l.Evidence)
+				entry.Expression = l.ID
+				entry.File = l.File
+				entry.Confidence = l.Confidence
+				entry.FileText = l.Text
+				entry.Embedded = embedded
+				entry.Copyrights = readCopyrights(fsys, sub, "")
+				_, entry.Notice = readNotice(fsys, sub)
+				entry.NoticeMissing = ""
+				c.add(entry)
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package licensecollector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAddEmbeddedLicenses(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"LICENSE":                         testMITText,
+		"third_party/lz4/LICENSE":         testBSD3Text,
+		"third_party/zlib-1.2.11/LICENSE": spdxLicenses["Zlib"].Text,
+		"vendor/github.com/x/y/LICENSE":   spdxLicenses["ISC"].Text,
+		// the same text as the package license
+		"vendor/github.com/x/z/LICENSE": testMITText,
+		"testdata/LICENSE":              spdxLicenses["Apache-2.0"].Text,
+	})
+	collection := &licenseCollection{}
+	collection.addEmbeddedLicenses(dirFS(dir), dependency{Name: "foo", LicenseDirs: []string{""}})
+	var got [][2]string
+	for _, e := range collection.entries {
+		got = append(got, [2]string{e.Embedded, e.Type})
+	}
+	want := [][2]string{
+		{"third_party/lz4", "BSD-3-Clause"},
+		{"third_party/zlib-1.2.11", "Zlib"},
+		{"vendor/github.com/x/y", "ISC"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("addEmbeddedLicenses() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,69 @@
+package licensecollector
+
+import (
+	"debug/buildinfo"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// readGoBinaryModules returns the modules linked into a go executable, from its embedded build info
+func readGoBinaryModules(fileName string) ([]goBuildModule, error) {
+	log.Println("Processing go binary: ", fileName)
+	info, err := buildinfo.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var modules []goBuildModule
+	for _, dep := range info.Deps {
+		m := goBuildModule{goModule: goModule{Path: dep.Path, Version: dep.Version}}
+		if dep.Replace != nil {
+			m.Replace = &goModule{Path: dep.Replace.Path, Version: dep.Replace.Version}
+			if m.Replace.Version == "(devel)" {
+				m.Replace.Version = ""
+			}
+		}
+		modules = append(modules, m)
+	}
+	return modules, nil
+}
+
+// collectGoBinary mounts the sources of the modules linked into a go executable.
+// The sources are taken from the vendor directory of tmpGoDir when it has one,
+// otherwise from the module cache. Local replacements are relative to tmpGoDir.
+func collectGoBinary(fileName string, tmpGoDir string) (mountFS, []goBuildModule, error) {
+	modules, err := readGoBinaryModules(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	fsys := mountFS{}
+	vendorDir := filepath.Join(tmpGoDir, "vendor")
+	if _, statErr := os.Stat(filepath.Join(vendorDir, vendorGoModuleFile)); len(tmpGoDir) > 0 && statErr == nil {
+		log.Println("Go vendor dir: ", vendorDir)
+		fsys[""] = dirFS(vendorDir)
+	}
+	cacheDir := ""
+	for _, m := range modules {
+		if m.Replace != nil && len(m.Replace.Version) == 0 {
+			fsys[m.Path] = dirFS(goLocalDir(tmpGoDir, m.Replace.Path))
+			continue
+		}
+		if _, ok := fsys[""]; ok {
+			continue
+		}
+		if len(cacheDir) == 0 {
+			cacheDir = goModCacheDir()
+			log.Println("Go module cache dir: ", cacheDir)
+		}
+		cached := m.goModule
+		if m.Replace != nil {
+			cached = *m.Replace
+		}
+		moduleFS, err := goCacheModuleFS(cacheDir, cached)
+		if err != nil {
+			return nil, nil, err
+		}
+		fsys[m.Path] = moduleFS
+	}
+	return fsys, modules, nil
+}
@@ -0,0 +1,279 @@
+package licensecollector
+
+import (
+	"bytes"
+	"fmt"
+	"go/build"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// goBuildContext returns the build context used to follow imports. Build tags,
+// GOOS and GOARCH select the files of every package, as they would for go build.
+func goBuildContext(tags []string, goos string, goarch string) *build.Context {
+	ctx := build.Default
+	if len(goos) > 0 {
+		ctx.GOOS = goos
+	}
+	if len(goarch) > 0 {
+		ctx.GOARCH = goarch
+	}
+	if ctx.GOOS != runtime.GOOS || ctx.GOARCH != runtime.GOARCH {
+		// cgo is disabled when cross compiling
+		ctx.CgoEnabled = false
+	}
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); len(tag) > 0 {
+			ctx.BuildTags = append(ctx.BuildTags, tag)
+		}
+	}
+	return &ctx
+}
+
+// goTestOnlyContext returns the build context finding the test only modules. Unless the
+// options select the source files, the project may be built for any platform: the files of
+// every platform are followed, only _test.go files are not.
+func goTestOnlyContext(opts Options) *build.Context {
+	selected := len(opts.GoOS) > 0 || len(opts.GoArch) > 0
+	for _, tag := range opts.GoBuildTags {
+		selected = selected || len(strings.TrimSpace(tag)) > 0
+	}
+	ctx := goBuildContext(opts.GoBuildTags, opts.GoOS, opts.GoArch)
+	ctx.UseAllFiles = !selected
+	return ctx
+}
+
+// goMainPackage is a main package of the project
+type goMainPackage struct {
+	// Binary names the notice of the binary: the package directory relative to its module,
+	// e.g. "cmd/server", or the last element of the module path for the module root
+	Binary     string
+	ImportPath string
+}
+
+// goImportGraph follows the imports of go packages through the collected module
+// sources, where every package directory is its import path
+type goImportGraph struct {
+	ctx build.Context
+	// modules are the module paths providing packages, longest first
+	modules []string
+	// mainModules maps the first party modules to their directory
+	mainModules map[string]string
+}
+
+// newGoImportGraph reads package sources from fsys, and the main modules from their directory
+func newGoImportGraph(ctx *build.Context, fsys mountFS, packages []goBuildModule, mainModules map[string]string) *goImportGraph {
+	sources := mountFS{}
+	for mount, moduleFS := range fsys {
+		sources[mount] = moduleFS
+	}
+	g := &goImportGraph{ctx: *ctx, mainModules: mainModules}
+	for modulePath, dir := range mainModules {
+		sources[modulePath] = dirFS(dir)
+		g.modules = append(g.modules, modulePath)
+	}
+	for _, m := range packages {
+		g.modules = append(g.modules, m.Path)
+	}
+	sort.Slice(g.modules, func(i, j int) bool { return len(g.modules[i]) > len(g.modules[j]) })
+
+	g.ctx.GOROOT = ""
+	g.ctx.GOPATH = ""
+	g.ctx.JoinPath = path.Join
+	g.ctx.IsAbsPath = func(string) bool { return false }
+	g.ctx.IsDir = func(dir string) bool {
+		_, err := sources.ReadDir(dir)
+		return err == nil
+	}
+	g.ctx.HasSubdir = func(root, dir string) (string, bool) { return "", false }
+	g.ctx.ReadDir = func(dir string) ([]os.FileInfo, error) {
+		entries, err := sources.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		res := make([]os.FileInfo, len(entries))
+		for i, e := range entries {
+			res[i] = sourceFileInfo{e}
+		}
+		return res, nil
+	}
+	g.ctx.OpenFile = func(name string) (io.ReadCloser, error) {
+		data, err := sources.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}
+	return g
+}
+
+// sourceFileInfo describes a directory entry of a sourceFS to go/build
+type sourceFileInfo struct{ dirEntry }
+
+func (fi sourceFileInfo) Name() string       { return fi.dirEntry.Name }
+func (fi sourceFileInfo) Size() int64        { return 0 }
+func (fi sourceFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi sourceFileInfo) IsDir() bool        { return fi.dirEntry.IsDir }
+func (fi sourceFileInfo) Sys() interface{}   { return nil }
+func (fi sourceFileInfo) Mode() os.FileMode {
+	if fi.IsDir() {
+		return os.ModeDir
+	}
+	return 0
+}
+
+// module returns the module providing an import path
+func (g *goImportGraph) module(importPath string) (string, bool) {
+	for _, m := range g.modules {
+		if importPath == m || strings.HasPrefix(importPath, m+"/") {
+			return m, true
+		}
+	}
+	return "", false
+}
+
+// isStandard checks if an import path belongs to the standard library
+func isStandard(importPath string) bool {
+	return !strings.Contains(strings.SplitN(importPath, "/", 2)[0], ".")
+}
+
+// importDir reads the package of an import path. With UseAllFiles, the files excluded from
+// every build, e.g. "//go:build ignore" generators, may belong to another package: the
+// package is kept, with their imports.
+func (g *goImportGraph) importDir(importPath string) (*build.Package, error) {
+	pkg, err := g.ctx.ImportDir(importPath, 0)
+	if _, ok := err.(*build.MultiplePackageError); ok && g.ctx.UseAllFiles {
+		return pkg, nil
+	}
+	return pkg, err
+}
+
+// walkMainModules calls fn for every package of the main modules. Nested modules,
+// vendor and testdata directories are skipped.
+func (g *goImportGraph) walkMainModules(fn func(pkg *build.Package)) {
+	for modulePath, dir := range g.mainModules {
+		_ = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+			if err != nil || !info.IsDir() {
+				return nil
+			}
+			name := info.Name()
+			if p != dir {
+				if name == "vendor" || name == "testdata" || name == nodeModules || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+					return filepath.SkipDir
+				}
+				if _, err := os.Stat(filepath.Join(p, goModFile)); err == nil {
+					return filepath.SkipDir
+				}
+			}
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return nil
+			}
+			pkg, err := g.importDir(path.Join(modulePath, filepath.ToSlash(rel)))
+			if err == nil {
+				fn(pkg)
+			}
+			return nil
+		})
+	}
+}
+
+// mainPackages finds the main packages of the main modules, cmd/* included. Main packages
+// of different workspace modules at the same relative directory would share a notice and
+// fail.
+func (g *goImportGraph) mainPackages() ([]goMainPackage, error) {
+	var res []goMainPackage
+	g.walkMainModules(func(pkg *build.Package) {
+		if pkg.Name != "main" {
+			return
+		}
+		modulePath, _ := g.module(pkg.Dir)
+		binary := strings.TrimPrefix(strings.TrimPrefix(pkg.Dir, modulePath), "/")
+		if len(binary) == 0 {
+			binary = path.Base(modulePath)
+		}
+		res = append(res, goMainPackage{Binary: binary, ImportPath: pkg.Dir})
+	})
+	sort.Slice(res, func(i, j int) bool { return res[i].ImportPath < res[j].ImportPath })
+	byBinary := map[string]string{}
+	for _, main := range res {
+		if other, ok := byBinary[main.Binary]; ok {
+			return nil, fmt.Errorf("main packages %s and %s have the same notice name %s", other, main.ImportPath, main.Binary)
+		}
+		byBinary[main.Binary] = main.ImportPath
+	}
+	return res, nil
+}
+
+// binaryModules returns the third party modules linked into the binary of a main package
+func (g *goImportGraph) binaryModules(main goMainPackage) map[string]struct{} {
+	return g.importedModules([]string{main.ImportPath})
+}
+
+// importedModules returns the third party modules imported by packages, directly or not.
+// Test files are not followed.
+func (g *goImportGraph) importedModules(importPaths []string) map[string]struct{} {
+	res := map[string]struct{}{}
+	seen := map[string]struct{}{}
+	var queue []string
+	for _, importPath := range importPaths {
+		if _, ok := seen[importPath]; !ok {
+			seen[importPath] = struct{}{}
+			queue = append(queue, importPath)
+		}
+	}
+	for len(queue) > 0 {
+		importPath := queue[0]
+		queue = queue[1:]
+		pkg, err := g.importDir(importPath)
+		if err != nil {
+			if _, ok := err.(*build.NoGoError); !ok {
+				log.Printf("failed reading package %s: %s\n", importPath, err)
+			}
+			continue
+		}
+		for _, imported := range pkg.Imports {
+			if _, ok := seen[imported]; ok || imported == "C" || isStandard(imported) {
+				continue
+			}
+			seen[imported] = struct{}{}
+			modulePath, ok := g.module(imported)
+			if !ok {
+				log.Printf("no module provides %s, imported by %s\n", imported, importPath)
+				continue
+			}
+			if _, ok := g.mainModules[modulePath]; !ok {
+				res[modulePath] = struct{}{}
+			}
+			queue = append(queue, imported)
+		}
+	}
+	return res
+}
+
+// testOnlyModules returns the modules of a module list that no production package of
+// the main modules imports: the modules imported by _test.go files only, and the
+// modules no package imports at all. Test helper packages of the main modules are
+// production packages, their imports are never test only.
+func (g *goImportGraph) testOnlyModules(modules []goBuildModule) map[string]struct{} {
+	var roots []string
+	g.walkMainModules(func(pkg *build.Package) {
+		roots = append(roots, pkg.Dir)
+	})
+	production := g.importedModules(roots)
+	res := map[string]struct{}{}
+	for _, m := range modules {
+		if _, ok := production[m.Path]; !ok {
+			res[m.Path] = struct{}{}
+		}
+	}
+	return res
+}
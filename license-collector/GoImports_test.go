@@ -0,0 +1,111 @@
+package licensecollector
+
+import (
+	"go/build"
+	"path"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestMainPackages(t *testing.T) {
+	tests := []struct {
+		name string
+		// modules maps the main module paths to their files
+		modules map[string]map[string]string
+		want    []goMainPackage
+		wantErr bool
+	}{
+		{
+			name: "binaries named after their directory",
+			modules: map[string]map[string]string{"example.com/app": {
+				"main.go":             "package main\n",
+				"cmd/a/main.go":       "package main\n",
+				"tools/a/main.go":     "package main\n",
+				"internal/lib/lib.go": "package lib\n",
+				"nested/go.mod":       "module example.com/nested\n",
+				"nested/main.go":      "package main\n",
+			}},
+			want: []goMainPackage{
+				{Binary: "app", ImportPath: "example.com/app"},
+				{Binary: "cmd/a", ImportPath: "example.com/app/cmd/a"},
+				{Binary: "tools/a", ImportPath: "example.com/app/tools/a"},
+			},
+		},
+		{
+			name: "workspace modules with the same binary directory",
+			modules: map[string]map[string]string{
+				"example.com/a": {"cmd/server/main.go": "package main\n"},
+				"example.com/b": {"cmd/server/main.go": "package main\n"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mainModules := map[string]string{}
+			for modulePath, files := range tt.modules {
+				dir := t.TempDir()
+				writeFiles(t, dir, files)
+				mainModules[modulePath] = dir
+			}
+			g := newGoImportGraph(&build.Default, mountFS{}, nil, mainModules)
+			got, err := g.mainPackages()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mainPackages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mainPackages() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTestOnlyModules(t *testing.T) {
+	project := t.TempDir()
+	writeFiles(t, project, map[string]string{
+		"cmd/b/main.go":      "package main\n\nfunc main() {}\n",
+		"cmd/b/x_windows.go": "package main\n\nimport _ \"example.com/win\"\n",
+		"cmd/b/x_plan9.go":   "//go:build plan9 && !cgo\n\npackage main\n\nimport _ \"example.com/plan9\"\n",
+		"lib/lib.go":         "package lib\n\nimport _ \"example.com/lib\"\n",
+		"lib/gen.go":         "//go:build ignore\n\npackage main\n\nimport _ \"example.com/gen\"\n",
+		"lib/lib_test.go":    "package lib\n\nimport _ \"example.com/test\"\n",
+	})
+	fsys := mountFS{}
+	var modules []goBuildModule
+	for _, m := range []string{"example.com/win", "example.com/plan9", "example.com/lib", "example.com/gen", "example.com/test", "example.com/unused"} {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{"m.go": "package " + path.Base(m) + "\n"})
+		fsys[m] = dirFS(dir)
+		modules = append(modules, goBuildModule{goModule: goModule{Path: m, Version: "v1.0.0"}})
+	}
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{
+			name: "every platform",
+			opts: Options{GoBuildTags: []string{""}},
+			want: []string{"example.com/test", "example.com/unused"},
+		},
+		{
+			name: "explicit GOOS",
+			opts: Options{GoOS: "linux", GoArch: "amd64"},
+			want: []string{"example.com/gen", "example.com/plan9", "example.com/test", "example.com/unused", "example.com/win"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newGoImportGraph(goTestOnlyContext(tt.opts), fsys, modules, map[string]string{"example.com/app": project})
+			var got []string
+			for m := range g.testOnlyModules(modules) {
+				got = append(got, m)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("testOnlyModules() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
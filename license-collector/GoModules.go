@@ -0,0 +1,482 @@
+package licensecollector
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const goModFile = "go.mod"
+const goSumFile = "go.sum"
+
+// goModule is a module path and version, as found in go.mod files
+type goModule struct {
+	Path    string
+	Version string
+}
+
+// goModReplace is a replace directive. A replacement without a version is a local directory.
+type goModReplace struct {
+	Old goModule
+	New goModule
+}
+
+// goBuildModule is a module of the build list and its replacement, if any
+type goBuildModule struct {
+	goModule
+	// Replace is the replacement module, a local directory when it has no version
+	Replace *goModule
+}
+
+// String returns "path@version", or the directory of a local module
+func (m goModule) String() string {
+	if len(m.Version) == 0 {
+		return m.Path
+	}
+	return m.Path + "@" + m.Version
+}
+
+// goModFileData holds the parts of a go.mod file needed to build the module graph
+type goModFileData struct {
+	Module  string
+	Go      string
+	Require []goModule
+	Replace []goModReplace
+	Exclude []goModule
+	// Use are the module directories of a go.work file
+	Use []string
+}
+
+// parseGoMod parses the content of a go.mod or go.work file
+func parseGoMod(data []byte) (*goModFileData, error) {
+	res := &goModFileData{}
+	block := ""
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(block) > 0 {
+			if fields[0] == ")" {
+				block = ""
+				continue
+			}
+			fields = append([]string{block}, fields...)
+		} else if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		for i := range fields {
+			if unquoted, err := strconv.Unquote(fields[i]); err == nil {
+				fields[i] = unquoted
+			}
+		}
+		var err error
+		switch fields[0] {
+		case "module":
+			if len(fields) < 2 {
+				err = errors.New("missing module path")
+				break
+			}
+			res.Module = fields[1]
+		case "go":
+			if len(fields) < 2 {
+				err = errors.New("missing go version")
+				break
+			}
+			res.Go = fields[1]
+		case "use":
+			if len(fields) != 2 {
+				err = errors.New("malformed use line")
+				break
+			}
+			res.Use = append(res.Use, fields[1])
+		case "require", "exclude":
+			if len(fields) != 3 {
+				err = fmt.Errorf("malformed %s line", fields[0])
+				break
+			}
+			m := goModule{Path: fields[1], Version: fields[2]}
+			if fields[0] == "require" {
+				res.Require = append(res.Require, m)
+			} else {
+				res.Exclude = append(res.Exclude, m)
+			}
+		case "replace":
+			arrow := -1
+			for i := range fields {
+				if fields[i] == "=>" {
+					arrow = i
+				}
+			}
+			if arrow < 2 || arrow > 3 || len(fields)-arrow < 2 || len(fields)-arrow > 3 {
+				err = errors.New("malformed replace line")
+				break
+			}
+			r := goModReplace{Old: goModule{Path: fields[1]}, New: goModule{Path: fields[arrow+1]}}
+			if arrow == 3 {
+				r.Old.Version = fields[2]
+			}
+			if len(fields)-arrow == 3 {
+				r.New.Version = fields[arrow+2]
+			}
+			res.Replace = append(res.Replace, r)
+		}
+		// other directives (toolchain, retract, godebug, ...) do not affect the build list
+		if err != nil {
+			return nil, fmt.Errorf("go.mod line %d: %s", lineNum, err)
+		}
+	}
+	return res, scanner.Err()
+}
+
+// replacement returns the replacement of m, if any. A replacement of a specific version wins over a general one.
+func (f *goModFileData) replacement(m goModule) (goModule, bool) {
+	var res goModule
+	found := false
+	for _, r := range f.Replace {
+		if r.Old.Path != m.Path {
+			continue
+		}
+		if r.Old.Version == m.Version {
+			return r.New, true
+		}
+		if len(r.Old.Version) == 0 {
+			res = r.New
+			found = true
+		}
+	}
+	return res, found
+}
+
+func (f *goModFileData) excluded(m goModule) bool {
+	for _, e := range f.Exclude {
+		if e == m {
+			return true
+		}
+	}
+	return false
+}
+
+// goModCacheDir returns the module cache directory, as the go command would use it
+func goModCacheDir() string {
+	if dir := os.Getenv("GOMODCACHE"); len(dir) > 0 {
+		return dir
+	}
+	if out, err := exec.Command("go", "env", "GOMODCACHE").Output(); err == nil {
+		if dir := strings.TrimSpace(string(out)); len(dir) > 0 {
+			return dir
+		}
+	}
+	gopath := os.Getenv("GOPATH")
+	if len(gopath) == 0 {
+		home, _ := os.UserHomeDir()
+		gopath = filepath.Join(home, "go")
+	}
+	return filepath.Join(filepath.SplitList(gopath)[0], "pkg", "mod")
+}
+
+// escapeModulePath escapes upper case letters the way the module cache stores them ("Azure" => "!azure")
+func escapeModulePath(p string) string {
+	var sb strings.Builder
+	for _, r := range p {
+		if 'A' <= r && r <= 'Z' {
+			sb.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// moduleCacheDownloadDir is where the go command keeps the .mod, .zip and .info files of a module
+func moduleCacheDownloadDir(cacheDir string, modulePath string) string {
+	return filepath.Join(cacheDir, "cache", "download", filepath.FromSlash(escapeModulePath(modulePath)), "@v")
+}
+
+// goModGraph walks the module graph and computes the build list using minimal version selection
+type goModGraph struct {
+	projectDir string
+	cacheDir   string
+	main       *goModFileData
+	// mainModules maps the first party modules to their directory: the main module,
+	// or the modules of a go.work workspace
+	mainModules map[string]string
+}
+
+// requirements returns the requirements of m, taking the main module replacements into account
+func (g *goModGraph) requirements(m goModule) ([]goModule, error) {
+	var data []byte
+	var err error
+	if r, ok := g.main.replacement(m); ok {
+		if len(r.Version) == 0 {
+			data, err = ioutil.ReadFile(filepath.Join(g.localDir(r.Path), goModFile))
+			if os.IsNotExist(err) {
+				// a local replacement without go.mod has no requirements
+				return nil, nil
+			}
+		} else {
+			m = r
+		}
+	}
+	if data == nil && err == nil {
+		data, err = ioutil.ReadFile(filepath.Join(moduleCacheDownloadDir(g.cacheDir, m.Path), escapeModulePath(m.Version)+".mod"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	f, err := parseGoMod(data)
+	if err != nil {
+		return nil, err
+	}
+	return f.Require, nil
+}
+
+// localDir resolves a local replacement directory relative to the main module
+func (g *goModGraph) localDir(dir string) string {
+	return goLocalDir(g.projectDir, dir)
+}
+
+// goLocalDir resolves a local replacement directory relative to the project directory
+func goLocalDir(projectDir string, dir string) string {
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(projectDir, filepath.FromSlash(dir))
+}
+
+func (g *goModGraph) isMainModule(modulePath string) bool {
+	_, ok := g.mainModules[modulePath]
+	return ok
+}
+
+// buildList returns the selected version of every module reachable from the main module
+func (g *goModGraph) buildList() []goModule {
+	selected := map[string]string{}
+	visited := map[goModule]struct{}{}
+	queue := append([]goModule{}, g.main.Require...)
+	var order []string
+	for len(queue) > 0 {
+		m := queue[0]
+		queue = queue[1:]
+		if _, ok := visited[m]; ok || g.main.excluded(m) || g.isMainModule(m.Path) {
+			continue
+		}
+		visited[m] = struct{}{}
+		current, ok := selected[m.Path]
+		if !ok {
+			order = append(order, m.Path)
+		}
+		if !ok || compareVersions(m.Version, current) > 0 {
+			selected[m.Path] = m.Version
+		}
+		reqs, err := g.requirements(m)
+		if err != nil {
+			// with module graph pruning the go.mod of modules that are not needed is never downloaded
+			log.Printf("skipping requirements of %s@%s: %s\n", m.Path, m.Version, err)
+			continue
+		}
+		queue = append(queue, reqs...)
+	}
+	res := make([]goModule, 0, len(order))
+	for _, p := range order {
+		res = append(res, goModule{Path: p, Version: selected[p]})
+	}
+	return res
+}
+
+// readGoSum returns the modules whose content (and not only go.mod) is listed in go.sum
+func readGoSum(fileName string) (map[goModule]struct{}, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	res := map[goModule]struct{}{}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/"+goModFile) {
+			continue
+		}
+		res[goModule{Path: fields[0], Version: fields[1]}] = struct{}{}
+	}
+	return res, nil
+}
+
+// goModuleFS locates the sources of module m: the replacement directory, the
+// extracted module in the cache, or the cached module zip
+func (g *goModGraph) goModuleFS(m goModule) (sourceFS, error) {
+	if r, ok := g.main.replacement(m); ok {
+		if len(r.Version) == 0 {
+			return dirFS(g.localDir(r.Path)), nil
+		}
+		m = r
+	}
+	return goCacheModuleFS(g.cacheDir, m)
+}
+
+// goCacheModuleFS locates the sources of module m in the module cache
+func goCacheModuleFS(cacheDir string, m goModule) (sourceFS, error) {
+	dir := filepath.Join(cacheDir, filepath.FromSlash(escapeModulePath(m.Path))+"@"+escapeModulePath(m.Version))
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return dirFS(dir), nil
+	}
+	zipFile := filepath.Join(moduleCacheDownloadDir(cacheDir, m.Path), escapeModulePath(m.Version)+".zip")
+	if _, err := os.Stat(zipFile); err == nil {
+		return zipFS{archive: zipFile, prefix: m.Path + "@" + m.Version}, nil
+	}
+	return nil, fmt.Errorf("module %s@%s is not in the module cache %s. make sure you 'go mod download'", m.Path, m.Version, cacheDir)
+}
+
+// readGoModFile reads and parses a go.mod or go.work file
+func readGoModFile(fileName string) (*goModFileData, error) {
+	log.Println("Processing go module file: ", fileName)
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return parseGoMod(data)
+}
+
+// loadGoModGraph loads the main module of a project and its go.sum, or the
+// workspace of a go.work project
+func loadGoModGraph(tmpGoDir string) (*goModGraph, map[goModule]struct{}, error) {
+	if _, err := os.Stat(filepath.Join(tmpGoDir, goWorkFile)); err == nil {
+		return loadGoWorkGraph(tmpGoDir)
+	}
+	mainModule, err := readGoModFile(filepath.Join(tmpGoDir, goModFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	graph := &goModGraph{projectDir: tmpGoDir, main: mainModule, mainModules: map[string]string{mainModule.Module: tmpGoDir}}
+	sums, err := readGoSum(filepath.Join(tmpGoDir, goSumFile))
+	if err != nil {
+		log.Printf("failed reading %s, using the full module graph: %s\n", goSumFile, err)
+	}
+	return graph, sums, nil
+}
+
+// collectGoModuleCache collects the build list of a module mode project from
+// go.mod and go.sum, and mounts every module found in the module cache under its module path
+func collectGoModuleCache(tmpGoDir string) (mountFS, []goBuildModule, error) {
+	graph, sums, err := loadGoModGraph(tmpGoDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	mainModule := graph.main
+	graph.cacheDir = goModCacheDir()
+	log.Println("Go module cache dir: ", graph.cacheDir)
+
+	fsys := mountFS{}
+	var packages []goBuildModule
+	for _, m := range graph.buildList() {
+		buildModule := goBuildModule{goModule: m}
+		if r, ok := mainModule.replacement(m); ok {
+			buildModule.Replace = &r
+		} else if _, ok := sums[m]; sums != nil && !ok {
+			// not needed for building any package
+			continue
+		}
+		moduleFS, err := graph.goModuleFS(m)
+		if err != nil {
+			return nil, nil, err
+		}
+		fsys[m.Path] = moduleFS
+		packages = append(packages, buildModule)
+	}
+	return fsys, packages, nil
+}
+
+// compareVersions compares two semantic versions, returning -1, 0 or 1
+func compareVersions(v, w string) int {
+	vMain, vPre := splitVersion(v)
+	wMain, wPre := splitVersion(w)
+	for i := 0; i < 3; i++ {
+		if c := compareNumeric(vMain[i], wMain[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case vPre == wPre:
+		return 0
+	case len(vPre) == 0:
+		return 1
+	case len(wPre) == 0:
+		return -1
+	}
+	vParts := strings.Split(vPre, ".")
+	wParts := strings.Split(wPre, ".")
+	for i := 0; i < len(vParts) && i < len(wParts); i++ {
+		if vParts[i] == wParts[i] {
+			continue
+		}
+		vNum := isNumeric(vParts[i])
+		wNum := isNumeric(wParts[i])
+		switch {
+		case vNum && wNum:
+			return compareNumeric(vParts[i], wParts[i])
+		case vNum:
+			return -1
+		case wNum:
+			return 1
+		case vParts[i] < wParts[i]:
+			return -1
+		default:
+			return 1
+		}
+	}
+	return compareNumeric(strconv.Itoa(len(vParts)), strconv.Itoa(len(wParts)))
+}
+
+// splitVersion splits "v1.2.3-pre+build" into its numeric parts and the pre-release
+func splitVersion(v string) ([3]string, string) {
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.Index(v, "+"); i >= 0 {
+		v = v[:i]
+	}
+	pre := ""
+	if i := strings.Index(v, "-"); i >= 0 {
+		pre = v[i+1:]
+		v = v[:i]
+	}
+	res := [3]string{"0", "0", "0"}
+	copy(res[:], strings.SplitN(v, ".", 3))
+	return res, pre
+}
+
+func isNumeric(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return len(s) > 0
+}
+
+// compareNumeric compares decimal strings of any length
+func compareNumeric(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
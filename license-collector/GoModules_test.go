@@ -0,0 +1,178 @@
+package licensecollector
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseGoMod(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *goModFileData
+		wantErr bool
+	}{
+		{
+			name: "single lines",
+			data: "module example.com/a\n\ngo 1.21\n\nrequire example.com/b v1.0.0 // indirect\n",
+			want: &goModFileData{Module: "example.com/a", Go: "1.21", Require: []goModule{{"example.com/b", "v1.0.0"}}},
+		},
+		{
+			name: "blocks",
+			data: "module example.com/a\nrequire (\n\texample.com/b v1.0.0\n\t\"example.com/c\" v1.2.0\n)\nexclude (\n\texample.com/b v0.9.0\n)\n",
+			want: &goModFileData{
+				Module:  "example.com/a",
+				Require: []goModule{{"example.com/b", "v1.0.0"}, {"example.com/c", "v1.2.0"}},
+				Exclude: []goModule{{"example.com/b", "v0.9.0"}},
+			},
+		},
+		{
+			name: "replacements",
+			data: "module example.com/a\nreplace example.com/b => ../b\nreplace (\n\texample.com/c v1.0.0 => example.com/d v1.1.0\n)\n",
+			want: &goModFileData{
+				Module: "example.com/a",
+				Replace: []goModReplace{
+					{Old: goModule{Path: "example.com/b"}, New: goModule{Path: "../b"}},
+					{Old: goModule{"example.com/c", "v1.0.0"}, New: goModule{"example.com/d", "v1.1.0"}},
+				},
+			},
+		},
+		{
+			name: "go.work",
+			data: "go 1.21\n\nuse (\n\t./a\n\t./b\n)\n",
+			want: &goModFileData{Go: "1.21", Use: []string{"./a", "./b"}},
+		},
+		{
+			name: "other directives",
+			data: "module example.com/a\ntoolchain go1.22.0\nretract v1.0.0\n",
+			want: &goModFileData{Module: "example.com/a"},
+		},
+		{name: "malformed require", data: "require example.com/b\n", wantErr: true},
+		{name: "malformed replace", data: "replace example.com/b ../b\n", wantErr: true},
+		{name: "missing module path", data: "module\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGoMod([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGoMod() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseGoMod() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplacement(t *testing.T) {
+	f := &goModFileData{Replace: []goModReplace{
+		{Old: goModule{Path: "example.com/b"}, New: goModule{Path: "../b"}},
+		{Old: goModule{"example.com/b", "v1.0.0"}, New: goModule{"example.com/c", "v1.0.0"}},
+	}}
+	tests := []struct {
+		m      goModule
+		want   goModule
+		wantOk bool
+	}{
+		{goModule{"example.com/b", "v1.0.0"}, goModule{"example.com/c", "v1.0.0"}, true},
+		{goModule{"example.com/b", "v2.0.0"}, goModule{Path: "../b"}, true},
+		{goModule{"example.com/d", "v1.0.0"}, goModule{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := f.replacement(tt.m)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("replacement(%s) = %s, %v, want %s, %v", tt.m, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		v, w string
+		want int
+	}{
+		{"v1.0.0", "v1.0.0", 0},
+		{"v1.2.0", "v1.10.0", -1},
+		{"v2.0.0", "v1.99.99", 1},
+		{"v1.0.0", "v1.0.0-rc.1", 1},
+		{"v1.0.0-alpha", "v1.0.0-beta", -1},
+		{"v1.0.0-rc.2", "v1.0.0-rc.10", -1},
+		{"v1.0.0-rc.1", "v1.0.0-rc", 1},
+		{"v1.0.0-1", "v1.0.0-alpha", -1},
+		{"v1.0.0+build", "v1.0.0", 0},
+		{"v0.0.0-20200101000000-abcdef", "v0.0.0-20210101000000-abcdef", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.v, tt.w); got != tt.want {
+			t.Errorf("compareVersions(%s, %s) = %d, want %d", tt.v, tt.w, got, tt.want)
+		}
+	}
+}
+
+func TestEscapeModulePath(t *testing.T) {
+	tests := []struct{ path, want string }{
+		{"github.com/Azure/azure-sdk", "github.com/!azure/azure-sdk"},
+		{"github.com/BurntSushi/toml", "github.com/!burnt!sushi/toml"},
+		{"golang.org/x/text", "golang.org/x/text"},
+	}
+	for _, tt := range tests {
+		if got := escapeModulePath(tt.path); got != tt.want {
+			t.Errorf("escapeModulePath(%s) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestReadGoSum(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), goSumFile)
+	data := "example.com/b v1.0.0 h1:abc=\nexample.com/b v1.0.0/go.mod h1:def=\nexample.com/c v1.1.0/go.mod h1:ghi=\n"
+	if err := ioutil.WriteFile(fileName, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readGoSum(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[goModule]struct{}{{"example.com/b", "v1.0.0"}: {}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readGoSum() = %v, want %v", got, want)
+	}
+}
+
+// writeCachedGoMod writes the go.mod of a module version to a module cache download directory
+func writeCachedGoMod(t *testing.T, cacheDir string, m goModule, data string) {
+	dir := moduleCacheDownloadDir(cacheDir, m.Path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, m.Version+".mod"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildList(t *testing.T) {
+	cacheDir := t.TempDir()
+	writeCachedGoMod(t, cacheDir, goModule{"example.com/b", "v1.0.0"}, "module example.com/b\nrequire example.com/c v1.2.0\n")
+	writeCachedGoMod(t, cacheDir, goModule{"example.com/c", "v1.1.0"}, "module example.com/c\n")
+	writeCachedGoMod(t, cacheDir, goModule{"example.com/c", "v1.2.0"}, "module example.com/c\nrequire example.com/a v1.0.0\n")
+	writeCachedGoMod(t, cacheDir, goModule{"example.com/e", "v1.0.0"}, "module example.com/e\n")
+
+	main := &goModFileData{
+		Module:  "example.com/a",
+		Require: []goModule{{"example.com/b", "v1.0.0"}, {"example.com/c", "v1.1.0"}, {"example.com/d", "v1.0.0"}},
+		Replace: []goModReplace{{Old: goModule{Path: "example.com/d"}, New: goModule{"example.com/e", "v1.0.0"}}},
+	}
+	g := &goModGraph{cacheDir: cacheDir, main: main, mainModules: map[string]string{"example.com/a": ""}}
+	want := []goModule{{"example.com/b", "v1.0.0"}, {"example.com/c", "v1.2.0"}, {"example.com/d", "v1.0.0"}}
+	if got := g.buildList(); !reflect.DeepEqual(got, want) {
+		t.Errorf("buildList() = %v, want %v", got, want)
+	}
+
+	main.Exclude = []goModule{{"example.com/c", "v1.2.0"}}
+	want = []goModule{{"example.com/b", "v1.0.0"}, {"example.com/c", "v1.1.0"}, {"example.com/d", "v1.0.0"}}
+	if got := g.buildList(); !reflect.DeepEqual(got, want) {
+		t.Errorf("buildList() with exclude = %v, want %v", got, want)
+	}
+}
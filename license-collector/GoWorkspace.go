@@ -0,0 +1,83 @@
+package licensecollector
+
+import (
+	"log"
+	"path/filepath"
+)
+
+const goWorkFile = "go.work"
+const goWorkSumFile = "go.work.sum"
+
+// loadGoWorkGraph loads a go.work workspace as a single main module: the requirements
+// of every used module, and their replacements unless go.work replaces the same module.
+// The used modules are first party, and so are the go.sum files of all of them.
+func loadGoWorkGraph(tmpGoDir string) (*goModGraph, map[goModule]struct{}, error) {
+	work, err := readGoModFile(filepath.Join(tmpGoDir, goWorkFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	main := &goModFileData{Go: work.Go, Replace: work.Replace}
+	mainModules := map[string]string{}
+	var sums map[goModule]struct{}
+	addSums := func(fileName string) {
+		fileSums, err := readGoSum(fileName)
+		if err != nil {
+			return
+		}
+		if sums == nil {
+			sums = map[goModule]struct{}{}
+		}
+		for m := range fileSums {
+			sums[m] = struct{}{}
+		}
+	}
+	addSums(filepath.Join(tmpGoDir, goWorkSumFile))
+	for _, use := range work.Use {
+		dir := goLocalDir(tmpGoDir, use)
+		f, err := readGoModFile(filepath.Join(dir, goModFile))
+		if err != nil {
+			return nil, nil, err
+		}
+		mainModules[f.Module] = dir
+		main.Require = append(main.Require, f.Require...)
+		main.Exclude = append(main.Exclude, f.Exclude...)
+		for _, r := range f.Replace {
+			if workReplaces(work, r.Old) {
+				continue
+			}
+			if len(r.New.Version) == 0 && !filepath.IsAbs(r.New.Path) {
+				// module replacements are relative to the module, not the workspace
+				r.New.Path = filepath.ToSlash(filepath.Join(use, r.New.Path))
+			}
+			main.Replace = append(main.Replace, r)
+		}
+		addSums(filepath.Join(dir, goSumFile))
+	}
+	log.Printf("found %d go workspace modules\n", len(mainModules))
+	return &goModGraph{projectDir: tmpGoDir, main: main, mainModules: mainModules}, sums, nil
+}
+
+// workReplaces checks if go.work replaces m, go.work replacements override the module ones
+func workReplaces(work *goModFileData, m goModule) bool {
+	for _, r := range work.Replace {
+		if r.Old.Path == m.Path && (len(r.Old.Version) == 0 || r.Old.Version == m.Version) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipGoWorkModules removes the modules used by a go.work workspace from a module list
+func skipGoWorkModules(tmpGoDir string, modules []goBuildModule) ([]goBuildModule, error) {
+	graph, _, err := loadGoWorkGraph(tmpGoDir)
+	if err != nil {
+		return nil, err
+	}
+	var res []goBuildModule
+	for _, m := range modules {
+		if !graph.isMainModule(m.Path) {
+			res = append(res, m)
+		}
+	}
+	return res, nil
+}
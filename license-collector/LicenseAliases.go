@@ -0,0 +1,114 @@
+package licensecollector
+
+import "strings"
+
+// licenseAliases maps the license names found in package metadata, older reports and
+// manualLicense.json, and the deprecated SPDX identifiers, to their canonical SPDX
+// expression. Keys are lower case.
+var licenseAliases = map[string]string{
+	// deprecated SPDX identifiers
+	"agpl-1.0":                         "AGPL-1.0-only",
+	"agpl-3.0":                         "AGPL-3.0-only",
+	"bsd-2-clause-freebsd":             "BSD-2-Clause",
+	"bsd-2-clause-netbsd":              "BSD-2-Clause",
+	"bzip2-1.0.5":                      "bzip2-1.0.6",
+	"ecos-2.0":                         "GPL-2.0-or-later WITH eCos-exception-2.0",
+	"gfdl-1.1":                         "GFDL-1.1-only",
+	"gfdl-1.2":                         "GFDL-1.2-only",
+	"gfdl-1.3":                         "GFDL-1.3-only",
+	"gpl-1.0":                          "GPL-1.0-only",
+	"gpl-1.0+":                         "GPL-1.0-or-later",
+	"gpl-2.0":                          "GPL-2.0-only",
+	"gpl-2.0+":                         "GPL-2.0-or-later",
+	"gpl-2.0-with-autoconf-exception":  "GPL-2.0-only WITH Autoconf-exception-2.0",
+	"gpl-2.0-with-bison-exception":     "GPL-2.0-only WITH Bison-exception-2.2",
+	"gpl-2.0-with-classpath-exception": "GPL-2.0-only WITH Classpath-exception-2.0",
+	"gpl-2.0-with-font-exception":      "GPL-2.0-only WITH Font-exception-2.0",
+	"gpl-2.0-with-gcc-exception":       "GPL-2.0-only WITH GCC-exception-2.0",
+	"gpl-3.0":                          "GPL-3.0-only",
+	"gpl-3.0+":                         "GPL-3.0-or-later",
+	"gpl-3.0-with-autoconf-exception":  "GPL-3.0-only WITH Autoconf-exception-3.0",
+	"gpl-3.0-with-gcc-exception":       "GPL-3.0-only WITH GCC-exception-3.1",
+	"lgpl-2.0":                         "LGPL-2.0-only",
+	"lgpl-2.0+":                        "LGPL-2.0-or-later",
+	"lgpl-2.1":                         "LGPL-2.1-only",
+	"lgpl-2.1+":                        "LGPL-2.1-or-later",
+	"lgpl-3.0":                         "LGPL-3.0-only",
+	"lgpl-3.0+":                        "LGPL-3.0-or-later",
+	"nunit":                            "zlib-acknowledgement",
+	"standardml-nj":                    "SMLNJ",
+	"wxwindows":                        "GPL-2.0-or-later WITH WxWindows-exception-3.1",
+
+	// go-license types of older reports and manual licenses
+	"newbsd":  "BSD-3-Clause",
+	"freebsd": "BSD-2-Clause",
+
+	// common names
+	"apache 2":                    "Apache-2.0",
+	"apache 2.0":                  "Apache-2.0",
+	"apache-2":                    "Apache-2.0",
+	"apache2":                     "Apache-2.0",
+	"apache license 2.0":          "Apache-2.0",
+	"apache license, version 2.0": "Apache-2.0",
+	"apache license version 2.0":  "Apache-2.0",
+	"apache software license 2.0": "Apache-2.0",
+	"asl 2.0":                     "Apache-2.0",
+	"bsd 2-clause":                "BSD-2-Clause",
+	"bsd-2":                       "BSD-2-Clause",
+	"simplified bsd":              "BSD-2-Clause",
+	"2-clause bsd":                "BSD-2-Clause",
+	"bsd 3-clause":                "BSD-3-Clause",
+	"bsd-3":                       "BSD-3-Clause",
+	"new bsd":                     "BSD-3-Clause",
+	"modified bsd":                "BSD-3-Clause",
+	"3-clause bsd":                "BSD-3-Clause",
+	"cc0":                         "CC0-1.0",
+	"expat":                       "MIT",
+	"mit license":                 "MIT",
+	"the mit license":             "MIT",
+	"isc license":                 "ISC",
+	"mpl 2.0":                     "MPL-2.0",
+	"mpl-2":                       "MPL-2.0",
+	"mozilla public license 2.0":  "MPL-2.0",
+	"the unlicense":               "Unlicense",
+	"gplv2":                       "GPL-2.0-only",
+	"gpl v2":                      "GPL-2.0-only",
+	"gpl-2":                       "GPL-2.0-only",
+	"gpl2":                        "GPL-2.0-only",
+	"gplv2+":                      "GPL-2.0-or-later",
+	"gplv3":                       "GPL-3.0-only",
+	"gpl v3":                      "GPL-3.0-only",
+	"gpl-3":                       "GPL-3.0-only",
+	"gpl3":                        "GPL-3.0-only",
+	"gplv3+":                      "GPL-3.0-or-later",
+	"lgplv2":                      "LGPL-2.0-only",
+	"lgplv2.1":                    "LGPL-2.1-only",
+	"lgplv2.1+":                   "LGPL-2.1-or-later",
+	"lgplv3":                      "LGPL-3.0-only",
+	"lgplv3+":                     "LGPL-3.0-or-later",
+	"agplv3":                      "AGPL-3.0-only",
+	"agplv3+":                     "AGPL-3.0-or-later",
+	"eclipse public license 2.0":  "EPL-2.0",
+	"eclipse public license 1.0":  "EPL-1.0",
+	"python software foundation":  "PSF-2.0",
+	"boost software license 1.0":  "BSL-1.0",
+	"creative commons zero v1.0":  "CC0-1.0",
+}
+
+// licenseAlias returns the canonical SPDX expression of a license alias or deprecated identifier
+func licenseAlias(name string) (string, bool) {
+	alias, ok := licenseAliases[strings.ToLower(strings.TrimSpace(name))]
+	return alias, ok
+}
+
+// licenseTextID returns the identifier of the text of a license, alias or deprecated identifier:
+// license texts do not tell "or later" versions apart, GPL-2.0-or-later is GPL-2.0-only
+func licenseTextID(id string) string {
+	id = normalizeLicenseID(id)
+	if version := strings.TrimSuffix(id, "-or-later"); version != id {
+		if l, ok := spdxLicenseByID(version + "-only"); ok {
+			return l.ID
+		}
+	}
+	return id
+}
@@ -0,0 +1,80 @@
+package licensecollector
+
+import "testing"
+
+func TestLicenseAlias(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   string
+		wantOk bool
+	}{
+		{"GPL-2.0", "GPL-2.0-only", true},
+		{"gpl-2.0+", "GPL-2.0-or-later", true},
+		{" Apache 2.0 ", "Apache-2.0", true},
+		{"GPLv2", "GPL-2.0-only", true},
+		{"NewBSD", "BSD-3-Clause", true},
+		{"wxWindows", "GPL-2.0-or-later WITH WxWindows-exception-3.1", true},
+		{"MIT", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := licenseAlias(tt.name)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("licenseAlias(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestNormalizeLicenseID(t *testing.T) {
+	tests := []struct{ lType, want string }{
+		{"mit", "MIT"},
+		{"apache-2.0", "Apache-2.0"},
+		{"Apache 2.0", "Apache-2.0"},
+		{"GPL-3.0", "GPL-3.0-only"},
+		{"FreeBSD", "BSD-2-Clause"},
+		{"Acme License", "Acme License"},
+	}
+	for _, tt := range tests {
+		if got := normalizeLicenseID(tt.lType); got != tt.want {
+			t.Errorf("normalizeLicenseID(%q) = %q, want %q", tt.lType, got, tt.want)
+		}
+	}
+}
+
+func TestLicenseTextID(t *testing.T) {
+	tests := []struct{ id, want string }{
+		{"GPL-2.0-or-later", "GPL-2.0-only"},
+		{"GPL-2.0+", "GPL-2.0-only"},
+		{"GPL-2.0", "GPL-2.0-only"},
+		{"lgpl-2.1-or-later", "LGPL-2.1-only"},
+		{"GFDL-1.3-or-later", "GFDL-1.3-only"},
+		{"MIT", "MIT"},
+	}
+	for _, tt := range tests {
+		if got := licenseTextID(tt.id); got != tt.want {
+			t.Errorf("licenseTextID(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestDeclaredLicenseMatches(t *testing.T) {
+	tests := []struct {
+		expression string
+		lType      string
+		want       bool
+	}{
+		{"MIT", "MIT", true},
+		{"mit", "MIT", true},
+		{"(MIT OR Apache-2.0)", "Apache-2.0", true},
+		{"Apache 2.0", "Apache-2.0", true},
+		{"GPLv2+", "GPL-2.0-only", true},
+		{"GPL-2.0-only", "GPL-2.0-or-later", true},
+		{"GPL-2.0-only WITH Classpath-exception-2.0", "GPL-2.0-only", true},
+		{"MIT", "ISC", false},
+		{"GPL-3.0-only", "GPL-2.0-only", false},
+	}
+	for _, tt := range tests {
+		if got := declaredLicenseMatches(tt.expression, tt.lType); got != tt.want {
+			t.Errorf("declaredLicenseMatches(%q, %q) = %v, want %v", tt.expression, tt.lType, got, tt.want)
+		}
+	}
+}
@@ -5,13 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"go/build"
 	"io/ioutil"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
-
-	"github.com/ryanuber/go-license"
 )
 
 // LicenseFileName is the default created license file name
@@ -19,32 +20,143 @@ const LicenseFileName = "THIRD_PARTY_LICENSE"
 const DefaultLicenseFileFormat = "txt"
 const vendorGoModuleFile = "modules.txt"
 
+// Go test only dependencies handling, see Options.GoTestDependencies
+const (
+	GoTestDependenciesExclude  = "exclude"
+	GoTestDependenciesSeparate = "separate"
+	GoTestDependenciesInclude  = "include"
+)
+
+// goTestOnlyGroup is the report section of the go modules imported by tests only
+const goTestOnlyGroup = "development only"
+
 // licenseMissing indicates that a license is missing
 var licenseMissing = false
 
+// Options configures a license collection
+type Options struct {
+	// ProjectGO is the go project directory
+	ProjectGO string
+	// GoBinary is a go executable whose linked modules are collected instead of the
+	// modules of ProjectGO. ProjectGO, if set, provides the vendor directory and manualLicense.json.
+	GoBinary string
+	// GoPerBinary creates a license file per main package of ProjectGO, e.g. cmd/*,
+	// listing the modules the binary imports
+	GoPerBinary bool
+	// GoTestDependencies handles the go modules no production package imports:
+	// exclude (the default) leaves them out, separate lists them in a development only
+	// section, include collects every module as is
+	GoTestDependencies string
+	// GoBuildTags, GoOS and GoArch select the source files followed by import analysis. When
+	// none is set, binaries are built for the current platform, and the test only modules are
+	// found from the files of every platform.
+	GoBuildTags []string
+	GoOS        string
+	GoArch      string
+	// ProjectNPM is the npm project directory
+	ProjectNPM string
+	// ProjectNodeModules holds node_modules, when it is not in ProjectNPM
+	ProjectNodeModules string
+	// NpmGroups are the package.json dependency groups to collect, dependencies by default
+	NpmGroups []string
+	// NpmWorkspaceNotices creates a license file per npm workspace instead of a combined one
+	NpmWorkspaceNotices bool
+	// FileName is the created license file
+	FileName string
+	// FileFormat is txt or json
+	FileFormat string
+	// LicensePreference are license identifiers in order of preference, the licenses a package
+	// with a choice of licenses (an OR expression) is complied under
+	LicensePreference []string
+	// ReviewThreshold is the confidence, in percent, under which a license decision needs review.
+	// The decisions to review are listed in the report and fail the collection.
+	ReviewThreshold float64
+	// DeepScan reports the license files found in the subdirectories of the packages, e.g. of a
+	// library copied into third_party/, as embedded components of the packages
+	DeepScan bool
+	// OriginalTexts reports the content of the license files of the packages, copyright
+	// lines included, instead of the SPDX license texts. Identical texts are reported once.
+	OriginalTexts bool
+}
+
 // Collect collects licenses from npm and or go projects
 func Collect(projectGO, projectNPM string, projectNodeModules string, fileName string, fileFormat string) error {
-	licenseMap := map[string][]string{}
-	foundManualLicense := map[string]string{}
+	return CollectWithOptions(Options{
+		ProjectGO:          projectGO,
+		ProjectNPM:         projectNPM,
+		ProjectNodeModules: projectNodeModules,
+		FileName:           fileName,
+		FileFormat:         fileFormat,
+	})
+}
+
+// CollectWithOptions collects licenses from npm and or go projects
+func CollectWithOptions(opts Options) error {
+	collection := &licenseCollection{preferred: opts.LicensePreference, deepScan: opts.DeepScan}
 
 	licenseMissing = false
 	var err error
-	if len(projectGO) > 0 {
-		err = collectGoLicenseFiles(projectGO, licenseMap, foundManualLicense)
+	if len(opts.GoBinary) > 0 {
+		err = collectGoBinaryLicenseFiles(opts.GoBinary, opts.ProjectGO, collection)
+	} else if len(opts.ProjectGO) > 0 {
+		err = collectGoLicenseFiles(opts.ProjectGO, opts, collection)
 	}
-	if len(projectNPM) > 0 {
-		err = collectNpmLicenseFiles(projectNPM, projectNodeModules, licenseMap, foundManualLicense)
+	if len(opts.ProjectNPM) > 0 {
+		err = collectNpmLicenseFiles(opts.ProjectNPM, opts.ProjectNodeModules, opts.NpmGroups, opts.NpmWorkspaceNotices, collection)
 	}
 	if err != nil {
 		return err
 	}
-	if len(licenseMap)+len(foundManualLicense) == 0 {
+	// every binary gets a notice, even when none links a third party module
+	if len(collection.entries) == 0 && len(collection.binaries) == 0 {
 		return errors.New("no licenses handled")
 	}
 	if licenseMissing {
 		return errors.New("license missing")
 	}
-	fileData, err := generateLicenseFile(licenseMap, foundManualLicense, fileFormat)
+	if err = writeLicenseFiles(collection, opts); err != nil {
+		return err
+	}
+	// the report is written for the review
+	if review := collection.reviewReport(opts.ReviewThreshold); len(review) > 0 {
+		return fmt.Errorf("licenses need review, confidence under %v%%:\n%s", opts.ReviewThreshold, strings.TrimSuffix(review, "\n"))
+	}
+	return nil
+}
+
+// writeLicenseFiles writes the license file, or a license file per go binary or npm workspace
+func writeLicenseFiles(collection *licenseCollection, opts Options) error {
+	if !opts.NpmWorkspaceNotices && !opts.GoPerBinary {
+		return writeLicenseFile(collection, opts.FileName, opts)
+	}
+	// everything that is not in a go binary or an npm workspace goes to the main file
+	notices, byNotice := collection.split(func(e *licenseEntry) string {
+		if len(e.Binary) > 0 {
+			return e.Binary
+		}
+		if opts.NpmWorkspaceNotices {
+			return e.Workspace
+		}
+		return ""
+	})
+	for _, binary := range collection.binaries {
+		if _, ok := byNotice[binary]; !ok {
+			notices = append(notices, binary)
+			byNotice[binary] = &licenseCollection{}
+		}
+	}
+	sort.Strings(notices)
+	for _, notice := range notices {
+		err := writeLicenseFile(byNotice[notice], noticeFileName(opts.FileName, notice), opts)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeLicenseFile(collection *licenseCollection, fileName string, opts Options) error {
+	fileData, err := generateLicenseFile(collection, opts)
 	if err != nil {
 		return err
 	}
@@ -56,21 +168,147 @@ func Collect(projectGO, projectNPM string, projectNodeModules string, fileName s
 	return nil
 }
 
-func collectGoLicenseFiles(tmpGoDir string, licenseMap map[string][]string, foundManualLicense map[string]string) error {
+// collectGoLicenseFiles collects the licenses of the go modules of a project. Per binary,
+// every main package gets the modules it imports, labeled with its binary.
+func collectGoLicenseFiles(tmpGoDir string, opts Options, collection *licenseCollection) error {
 	dir := filepath.Join(tmpGoDir, "vendor")
-	log.Println("Go Project dir: ", dir)
 	// test go modules
 	fileName := filepath.Join(dir, vendorGoModuleFile)
-	log.Println("Processing go module file: ", fileName)
-	fileHandle, err := os.Open(fileName)
+	var fsys mountFS
+	var packages []goBuildModule
+	var err error
+	if _, statErr := os.Stat(fileName); statErr == nil {
+		log.Println("Go Project dir: ", dir)
+		packages, err = readVendorModules(fileName)
+		if _, statErr := os.Stat(filepath.Join(tmpGoDir, goWorkFile)); err == nil && statErr == nil {
+			// the workspace vendor directory is at the workspace root, its modules are first party
+			packages, err = skipGoWorkModules(tmpGoDir, packages)
+		}
+		// local replacements are read from their directory, not from vendor
+		vendorFS := mountFS{"": dirFS(dir)}
+		for _, m := range packages {
+			if m.Replace != nil && len(m.Replace.Version) == 0 {
+				vendorFS[m.Path] = dirFS(goLocalDir(tmpGoDir, m.Replace.Path))
+			}
+		}
+		fsys = vendorFS
+	} else {
+		log.Printf("no %s found, using the module cache\n", vendorGoModuleFile)
+		fsys, packages, err = collectGoModuleCache(tmpGoDir)
+	}
+	if err != nil {
+		log.Println(err)
+		log.Println("Failed processing go licenses")
+		return err
+	}
+
+	ctx := goBuildContext(opts.GoBuildTags, opts.GoOS, opts.GoArch)
+	if opts.GoPerBinary {
+		return parseGoBinaries(fsys, packages, tmpGoDir, ctx, collection)
+	}
+	switch opts.GoTestDependencies {
+	case GoTestDependenciesInclude:
+		return parseGoModules(fsys, packages, tmpGoDir, collection)
+	case "", GoTestDependenciesExclude, GoTestDependenciesSeparate:
+	default:
+		return fmt.Errorf("unknown go test dependencies handling %s", opts.GoTestDependencies)
+	}
+	graph, _, err := loadGoModGraph(tmpGoDir)
+	if err != nil {
+		return err
+	}
+	testOnly := newGoImportGraph(goTestOnlyContext(opts), fsys, packages, graph.mainModules).testOnlyModules(packages)
+	manualLicense, err := prepareManualLicense(tmpGoDir)
+	if err != nil {
+		return err
+	}
+	for _, m := range packages {
+		dep := goDependency(m, packages)
+		if _, ok := testOnly[m.Path]; ok {
+			if opts.GoTestDependencies != GoTestDependenciesSeparate {
+				log.Printf("skipping %s, no production package imports it\n", m.Path)
+				continue
+			}
+			dep.Group = goTestOnlyGroup
+		}
+		doParseFile(fsys, dep, manualLicense, collection)
+	}
+	return nil
+}
+
+// parseGoBinaries follows the imports of every main package, and collects the modules of each binary
+func parseGoBinaries(fsys mountFS, packages []goBuildModule, tmpGoDir string, ctx *build.Context, collection *licenseCollection) error {
+	graph, _, err := loadGoModGraph(tmpGoDir)
+	if err != nil {
+		return err
+	}
+	imports := newGoImportGraph(ctx, fsys, packages, graph.mainModules)
+	mains, err := imports.mainPackages()
+	if err != nil {
+		return err
+	}
+	if len(mains) == 0 {
+		return errors.New("no main packages found in " + tmpGoDir)
+	}
+	manualLicense, err := prepareManualLicense(tmpGoDir)
+	if err != nil {
+		return err
+	}
+	for _, main := range mains {
+		modules := imports.binaryModules(main)
+		log.Printf("binary %s imports %d modules\n", main.Binary, len(modules))
+		// a binary without third party modules gets an empty notice
+		collection.binaries = append(collection.binaries, main.Binary)
+		for _, m := range packages {
+			if _, ok := modules[m.Path]; !ok {
+				continue
+			}
+			dep := goDependency(m, packages)
+			dep.Binary = main.Binary
+			doParseFile(fsys, dep, manualLicense, collection)
+		}
+	}
+	return nil
+}
+
+// collectGoBinaryLicenseFiles collects the licenses of the modules linked into a go executable
+func collectGoBinaryLicenseFiles(binary string, tmpGoDir string, collection *licenseCollection) error {
+	fsys, packages, err := collectGoBinary(binary, tmpGoDir)
 	if err != nil {
 		log.Println(err)
-		log.Printf("failed finding %s for third party packages. make sure you 'go mod vendor'\n", vendorGoModuleFile)
+		log.Println("Failed processing go binary licenses")
 		return err
 	}
+	manualDir := tmpGoDir
+	if len(manualDir) == 0 {
+		manualDir = filepath.Dir(binary)
+	}
+	return parseGoModules(fsys, packages, manualDir, collection)
+}
+
+func parseGoModules(fsys sourceFS, packages []goBuildModule, manualDir string, collection *licenseCollection) error {
+	manualLicense, err := prepareManualLicense(manualDir)
+	if err != nil {
+		return err
+	}
+	for _, m := range packages {
+		doParseFile(fsys, goDependency(m, packages), manualLicense, collection)
+	}
+	return nil
+}
+
+// readVendorModules returns the modules listed in vendor/modules.txt,
+// "# path version" or "# path [version] => replacement [version]"
+func readVendorModules(fileName string) ([]goBuildModule, error) {
+	log.Println("Processing go module file: ", fileName)
+	fileHandle, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
 	defer func() { _ = fileHandle.Close() }()
 
 	packageMap := make(map[string]struct{})
+	var packages []goBuildModule
 	fileScanner := bufio.NewScanner(fileHandle)
 	for fileScanner.Scan() {
 		line := strings.TrimSpace(fileScanner.Text())
@@ -81,142 +319,300 @@ func collectGoLicenseFiles(tmpGoDir string, licenseMap map[string][]string, foun
 		if strings.Index(line, "#") != 0 {
 			continue
 		}
-		linePackage := strings.SplitN(line, " ", 3)[1]
-		if len(linePackage) > 0 {
-			packageMap[linePackage] = struct{}{}
+		fields := strings.Fields(line)[1:]
+		if len(fields) == 0 {
+			continue
+		}
+		m := goBuildModule{goModule: goModule{Path: fields[0]}}
+		for i := 1; i < len(fields); i++ {
+			if fields[i] != "=>" {
+				m.Version = fields[i]
+				continue
+			}
+			if i+1 < len(fields) {
+				m.Replace = &goModule{Path: fields[i+1]}
+				if i+2 < len(fields) {
+					m.Replace.Version = fields[i+2]
+				}
+			}
+			break
+		}
+		if _, ok := packageMap[m.Path]; !ok {
+			packageMap[m.Path] = struct{}{}
+			packages = append(packages, m)
 		}
 	}
-
-	manualLicense, err := prepareManualLicense(tmpGoDir)
-	if err != nil {
-		return err
-	}
-	for packagePath := range packageMap {
-		doParseFile(dir, packagePath, manualLicense, licenseMap, foundManualLicense)
-	}
-	return nil
+	return packages, fileScanner.Err()
 }
 
-func collectNpmLicenseFiles(tmpNpmDir string, tmpNodeModulesDir string, licenseMap map[string][]string, foundManualLicense map[string]string) error {
+// collectNpmLicenseFiles collects the packages of the project and of its workspaces.
+// Without perWorkspace the workspaces share a single combined list.
+func collectNpmLicenseFiles(tmpNpmDir string, tmpNodeModulesDir string, groups []string, perWorkspace bool, collection *licenseCollection) error {
 	log.Println("NPM Project dir: ", tmpNpmDir)
+	if len(groups) == 0 {
+		groups = []string{npmDependencies}
+	}
+	for _, group := range groups {
+		if !InStringSlice(npmGroups, group) {
+			return fmt.Errorf("unknown npm dependency group %s, expected one of %v", group, npmGroups)
+		}
+	}
 	nodeModulesDir := tmpNpmDir
 	if len(tmpNodeModulesDir) > 0 {
 		nodeModulesDir = tmpNodeModulesDir
 	}
-	dir := filepath.Join(nodeModulesDir, "node_modules")
-	fileName := filepath.Join(tmpNpmDir, "package.json")
-	log.Println("Processing package file: ", fileName)
-	data, err := ioutil.ReadFile(fileName)
+	dir := filepath.Join(nodeModulesDir, nodeModules)
+	workspaces, err := findNpmWorkspaces(tmpNpmDir)
+	if err != nil {
+		log.Println("Failed processing npm workspaces")
+		return err
+	}
+	var fsys sourceFS = npmSourceFS(tmpNpmDir, dir, workspaces)
+	var packages []npmPackage
+	lockFileName := filepath.Join(tmpNpmDir, npmPackageLockFile)
+	yarnFileName := filepath.Join(tmpNpmDir, yarnLockFile)
+	pnpmFileName := filepath.Join(tmpNpmDir, pnpmLockFile)
+	if _, statErr := os.Stat(lockFileName); statErr == nil {
+		packages, err = readPackageLock(lockFileName, fsys, workspaces)
+	} else if _, statErr := os.Stat(yarnFileName); statErr == nil {
+		fsys, packages, err = collectYarnPackages(yarnFileName, tmpNpmDir, dir, workspaces)
+	} else if _, statErr := os.Stat(pnpmFileName); statErr == nil {
+		fsys, packages, err = collectPnpmPackages(pnpmFileName, tmpNpmDir, dir, workspaces)
+	} else {
+		log.Println("no lock file found, using the direct dependencies only")
+		packages, err = readPackageJSONDependencies(workspaces)
+	}
 	if err != nil {
 		log.Println(err)
 		log.Println("Failed processing npm licenses")
 		return err
 	}
-
-	packageMap := map[string]interface{}{}
-	_ = json.Unmarshal(data, &packageMap)
-	//Get the package list
-	rawPackages, ok := packageMap["dependencies"]
-	var packages map[string]interface{}
-	if ok {
-		packages = rawPackages.(map[string]interface{})
+	if !perWorkspace {
+		packages = mergeWorkspacePackages(packages)
 	}
 
 	manualLicense, err := prepareManualLicense(tmpNpmDir)
 	if err != nil {
 		return err
 	}
-	for fileDir := range packages {
-		doParseFile(dir, fileDir, manualLicense, licenseMap, foundManualLicense)
+	for _, p := range packages {
+		if !InStringSlice(groups, p.Group) {
+			continue
+		}
+		dep := p.dependency()
+		if packageJSON := readInstalledPackageJSON(fsys, p); packageJSON != nil {
+			dep.Declared = packageJSON.declaredLicense()
+			dep.Author = packageJSON.author()
+			dep.ShipsNotice = packageJSON.shipsNotice()
+		}
+		doParseFile(fsys, dep, manualLicense, collection)
 	}
 	return nil
 }
 
-func doParseFile(dir, fileDir string, manualLicense map[string]string, licenseMap map[string][]string, foundManualLicense map[string]string) {
-	lDir, licenseDescriptor, missing := parseLicenseManual(fileDir, manualLicense)
+// dependency is a third party package whose license is collected
+type dependency struct {
+	// Name is reported when no license is found
+	Name string
+	// PackageName is the reported package name, the license directory when empty
+	PackageName string
+	// Version is the package version, if known
+	Version string
+	// Path is the install path of the package, if it has one
+	Path string
+	// Replace is the replacement of a go module, "path@version" or a local directory
+	Replace string
+	// Binary is the go binary importing the package, for per binary notices
+	Binary string
+	// LicenseDirs are the directories that may hold the license, closest to the package first
+	LicenseDirs []string
+	// ManualKeys are the manualLicense.json keys matching the package, in lookup order
+	ManualKeys []string
+	// Declared is the license expression declared by the package metadata, if any
+	Declared string
+	// Group is the dependency group the package came from
+	Group string
+	// Workspace is the npm workspace using the package, empty for the root project
+	Workspace string
+	// Author is the package author declared by the package metadata, if any
+	Author string
+	// Copyrights are the copyright statements found in the package directory
+	Copyrights []copyright
+	// ShipsNotice is set when the package metadata says the package has a NOTICE file
+	ShipsNotice bool
+	// Notice is the content of the NOTICE file of the package
+	Notice string
+	// Nested are the directories of other packages inside the package directory
+	Nested []string
+}
+
+// entry creates the license entry of the package, found in lDir, with the confidence of its evidence type
+func (dep dependency) entry(lDir string, lType string, text string, evidence string) licenseEntry {
+	name := dep.PackageName
+	if len(name) == 0 {
+		name = lDir
+	}
+	return licenseEntry{Name: name, Version: dep.Version, Path: dep.Path, Replace: dep.Replace, Binary: dep.Binary, Group: dep.Group, Workspace: dep.Workspace, Type: lType, Text: text, Copyrights: dep.Copyrights, Notice: dep.Notice, NoticeMissing: dep.noticeMissing(), Evidence: evidence, Confidence: evidenceConfidence[evidence]}
+}
+
+// noticeMissing tells why the package should have a NOTICE file, when it has none
+func (dep dependency) noticeMissing() string {
+	if dep.ShipsNotice && len(dep.Notice) == 0 {
+		return "the package.json files list a NOTICE file, but it is not installed"
+	}
+	return ""
+}
+
+// goDependency searches the license of a go module at the module root, the walk stops
+// at the module boundary: a parent directory belongs to another module, and so do the
+// directories of the nested modules. The manualLicense.json keys are matched from the
+// top of the module path down.
+func goDependency(m goBuildModule, modules []goBuildModule) dependency {
+	var dirs []string
+	currentDir := ""
+	for _, dir := range strings.Split(m.Path, "/") {
+		currentDir = path.Join(currentDir, dir)
+		dirs = append(dirs, currentDir)
+	}
+	dep := dependency{Name: m.Path, PackageName: m.Path, Version: m.Version, LicenseDirs: []string{m.Path}, ManualKeys: dirs}
+	for _, nested := range modules {
+		if strings.HasPrefix(nested.Path, m.Path+"/") {
+			dep.Nested = append(dep.Nested, nested.Path)
+		}
+	}
+	if m.Replace != nil {
+		dep.Replace = m.Replace.String()
+	}
+	return dep
+}
+
+func doParseFile(fsys sourceFS, dep dependency, manualLicense map[string]string, collection *licenseCollection) {
+	if len(dep.LicenseDirs) > 0 {
+		dep.Copyrights = readCopyrights(fsys, dep.LicenseDirs[0], dep.Author)
+		var noticeFile string
+		if noticeFile, dep.Notice = readNotice(fsys, dep.LicenseDirs[0]); len(noticeFile) > 0 {
+			log.Println("Found NOTICE file", noticeFile)
+		} else if dep.ShipsNotice {
+			log.Printf("Missing NOTICE file for %s: %s\n", dep.Name, dep.noticeMissing())
+		}
+	}
+	lDir, licenseDescriptor, missing := parseLicenseManual(dep, manualLicense)
+	if collection.deepScan && licenseDescriptor != "ignore" {
+		defer collection.addEmbeddedLicenses(fsys, dep)
+	}
 	if missing {
-		lDir, lType, missing := parseLicenseAuto(dir, fileDir)
-		lDir = lDir[len(dir)+1:]
+		lDir, licenses, missing := parseLicenseAuto(fsys, dep)
+		var detected *licenseExpression
+		if !missing {
+			detected = licenseFilesExpression(licenses)
+		} else if len(dep.Declared) > 0 {
+			// no license file, the declared license is the next best evidence
+			log.Printf("Using the declared license %s for %s\n", dep.Declared, lDir)
+			addDeclaredLicense(collection, lDir, dep)
+			return
+		} else if len(dep.LicenseDirs) > 0 {
+			// nothing declared either, the SPDX-License-Identifier headers of the sources are the last resort
+			if detected, licenses = scanLicenseHeaders(fsys, dep.LicenseDirs[0]); detected != nil {
+				lDir, missing = dep.LicenseDirs[0], false
+				log.Printf("Using the SPDX-License-Identifier headers %s for %s\n", detected, lDir)
+			}
+		}
 		if missing {
 			log.Println("Could not find license for ", lDir)
 			licenseMissing = true
 		}
-		if lType != "" {
-			arr := licenseMap[lType]
-			if !InStringSlice(arr, lDir) {
-				arr = append(arr, lDir)
-				licenseMap[lType] = arr
+		if detected == nil {
+			return
+		}
+		// every license file of the package is reported, under the declared expression when it covers them
+		expression, uncovered := coveringLicenseExpression(detected, licenses, dep.Declared)
+		for _, e := range collection.addExpression(dep, lDir, expression, licenses, evidenceMetadata) {
+			for _, l := range uncovered {
+				if e.File == l.File {
+					e.Conflict = fmt.Sprintf("declared license %s, license file is %s", dep.Declared, l.ID)
+					log.Printf("License conflict for %s: %s\n", e.label(), e.Conflict)
+				}
 			}
 		}
 	} else if len(licenseDescriptor) > 0 {
-		//License can be either a single word, then we will check in the licenseMap
-		//If it is more than one word, we will simply place it there ...
+		//License can be either a license expression of known licenses, then we will check in the license list
+		//Otherwise, we will simply place it there ...
 		if licenseDescriptor == "ignore" {
 			return
 		}
-		if strings.Index(licenseDescriptor, " ") == -1 {
-			arr, exists := licenseMap[licenseDescriptor]
-			if exists {
-				if !InStringSlice(arr, lDir) {
-					arr = append(arr, lDir)
-					licenseMap[licenseDescriptor] = arr
-				}
-			} else {
-				foundManualLicense[lDir] = licenseDescriptor
-			}
+		if expression, ok := knownLicenseExpression(licenseDescriptor); ok {
+			collection.addExpression(dep, lDir, expression, nil, evidenceManual)
 		} else {
-			foundManualLicense[lDir] = licenseDescriptor
+			collection.add(dep.entry(lDir, "", licenseDescriptor, evidenceManual))
 		}
 	}
 }
 
-func generateLicenseFile(lTypeMap map[string][]string, lContentMap map[string]string, format string) ([]byte, error) {
-	licenseMap := initLicenseMap()
-	res := ""
-	jsonRes := map[string]string{}
-	wrongLicense := map[string][]string{}
-	for k, v := range lTypeMap {
-		fullLicense, ok := licenseMap[k]
-		if !ok {
-			wrongLicense[k] = v
-			continue
-		}
-		projects := ""
-		for _, p := range v {
-			projects += p + "\n"
-			jsonRes[p] = fullLicense
-		}
-		projects += fullLicense + "\n"
-		res += projects
+// addDeclaredLicense adds a package by its declared license. An expression of known
+// licenses gets the full license texts, anything else is placed as is.
+func addDeclaredLicense(collection *licenseCollection, lDir string, dep dependency) {
+	if expression, ok := knownLicenseExpression(dep.Declared); ok {
+		collection.addExpression(dep, lDir, expression, nil, evidenceMetadata)
+		return
 	}
-	if len(wrongLicense) > 0 {
-		errMsg := "Wrong license files for the following libs"
-		for k, v := range wrongLicense {
-			errMsg += "\n " + k + ": " + fmt.Sprintf("%v", v)
+	collection.add(dep.entry(lDir, "", "License: "+dep.Declared, evidenceMetadata))
+}
+
+// knownLicenseExpression parses a license expression whose licenses all have a text
+func knownLicenseExpression(s string) (*licenseExpression, bool) {
+	// names with spaces, e.g. "Apache 2.0", are not expressions
+	if alias, ok := licenseAlias(s); ok {
+		s = alias
+	}
+	expression, err := parseLicenseExpression(s)
+	if err != nil || expression.validate() != nil {
+		return nil, false
+	}
+	for _, l := range expression.licenses() {
+		if _, ok := licenseText(l.String()); !ok {
+			return nil, false
 		}
-		var errRes []byte
-		if format == "json" {
-			errRes = []byte("{}")
-		} else {
-			errRes = []byte("")
+	}
+	return expression, true
+}
+
+// licenseFilesExpression returns the expression of license files: all their licenses apply
+func licenseFilesExpression(licenses []licenseFile) *licenseExpression {
+	expression := &licenseExpression{Op: licenseAnd}
+	seen := map[string]struct{}{}
+	for _, l := range licenses {
+		if _, ok := seen[l.ID]; !ok {
+			seen[l.ID] = struct{}{}
+			expression.Args = append(expression.Args, &licenseExpression{License: l.ID})
 		}
-		return errRes, fmt.Errorf(errMsg)
 	}
-	for project, fullLicense := range lContentMap {
-		res += project + "\n" + fullLicense + "\n"
-		jsonRes[project] = fullLicense
+	if len(expression.Args) == 1 {
+		return expression.Args[0]
 	}
-	var bRes []byte
-	if format == "json" {
-		var err error
-		bRes, err = json.Marshal(jsonRes)
-		if err != nil {
-			return []byte("{}"), err
+	return expression
+}
+
+// coveringLicenseExpression returns the license expression of a package: the declared expression
+// when it covers every detected license, the detected expression otherwise. uncovered are the
+// license files the declared expression does not allow.
+func coveringLicenseExpression(detected *licenseExpression, licenses []licenseFile, declared string) (expression *licenseExpression, uncovered []licenseFile) {
+	if len(declared) == 0 {
+		return detected, nil
+	}
+	for _, l := range licenses {
+		if !declaredLicenseMatches(declared, l.ID) {
+			uncovered = append(uncovered, l)
 		}
-	} else {
-		bRes = []byte(res)
 	}
-	return bRes, nil
+	if e, ok := knownLicenseExpression(declared); ok && len(uncovered) == 0 {
+		return e, nil
+	}
+	return detected, uncovered
+}
+
+// licenseMatchesFile checks if a license file was detected as a license of an expression
+func licenseMatchesFile(l *licenseExpression, l2 licenseFile) bool {
+	return strings.EqualFold(licenseTextID(l.License), licenseTextID(l2.ID))
 }
 
 // InStringSlice checks if val string is in s slice, case insensitive.
@@ -229,27 +625,113 @@ func InStringSlice(slice []string, val string) bool {
 	return false
 }
 
-func parseLicenseAuto(dir, fileDir string) (lDir string, lType string, missing bool) {
-	// This case will work if there is a guessable license file in the
-	// current working directory.
-	dirs := strings.Split(fileDir, "/")
-	currentDir := dir
+// licenseFile is a license file and the license detected in it
+type licenseFile struct {
+	File string
+	Text string
+	licenseMatch
+	// Evidence is the evidence type of the match
+	Evidence string
+}
+
+// parseLicenseAuto finds the license files closest to the package: the first of
+// dep.LicenseDirs holding a license file wins
+func parseLicenseAuto(fsys sourceFS, dep dependency) (lDir string, licenses []licenseFile, missing bool) {
 	missing = true
-	lDir = filepath.Join(dir, fileDir)
-	for i := range dirs {
-		currentDir = filepath.Join(currentDir, dirs[i])
-		l, err := license.NewFromDir(currentDir)
+	lDir = dep.Name
+	for _, currentDir := range dep.LicenseDirs {
+		l, err := licensesFromFS(fsys, currentDir)
 		if err != nil {
 			continue
 		}
 		missing = false
-		lType = l.Type
+		licenses = l
 		lDir = currentDir
 		break
 	}
 	return
 }
 
+// licenseFileNames are the names of license files, case insensitive
+var licenseFileNames = []string{
+	"license", "license.txt", "license.md",
+	"licence", "licence.txt", "licence.md",
+	"copying", "copying.txt", "copying.md",
+	"unlicense",
+}
+
+// licenseFilePrefixes are the prefixes of license files of a single license out of several,
+// e.g. LICENSE-MIT and LICENSE-APACHE
+var licenseFilePrefixes = []string{"license-", "licence-", "copying-", "license_", "licence_"}
+
+// licenseTextExtensions are the extensions of prefixed license files, e.g. LICENSE-MIT.txt.
+// Other files with a license prefix are sources, e.g. license_test.go.
+var licenseTextExtensions = []string{"", ".txt", ".md", ".markdown", ".rst"}
+
+var errNoLicenseFile = errors.New("license: unable to find any license file")
+
+// isTextFileName checks if a file name has a text extension, or a version suffix
+// taken as an extension, e.g. LICENSE-APACHE-2.0
+func isTextFileName(name string) bool {
+	ext := path.Ext(name)
+	return InStringSlice(licenseTextExtensions, ext) || isNumeric(strings.TrimPrefix(ext, "."))
+}
+
+// isLicenseFile checks if a file name is a license file name, case insensitive
+func isLicenseFile(name string) bool {
+	if InStringSlice(licenseFileNames, name) {
+		return true
+	}
+	for _, prefix := range licenseFilePrefixes {
+		if len(name) > len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+			return isTextFileName(name)
+		}
+	}
+	return false
+}
+
+// licensesFromFS returns every license file of a directory whose license is detected
+func licensesFromFS(fsys sourceFS, dir string) ([]licenseFile, error) {
+	files, err := fsys.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, file := range files {
+		if !file.IsDir && isLicenseFile(file.Name) {
+			matches = append(matches, file.Name)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, errNoLicenseFile
+	}
+	sort.Strings(matches)
+	var res []licenseFile
+	for _, match := range matches {
+		fileName := path.Join(dir, match)
+		text, err := fsys.ReadFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+		m, err := matchLicense(string(text))
+		if err != nil {
+			log.Printf("Could not detect the license of %s: %s\n", fileName, err)
+			continue
+		}
+		m.ID = normalizeLicenseID(m.ID)
+		// only a copy of the template word for word, in order, is exact text
+		evidence := evidenceFuzzyText
+		if m.Exact {
+			evidence = evidenceExactText
+		}
+		res = append(res, licenseFile{File: fileName, Text: string(text), licenseMatch: m, Evidence: evidence})
+	}
+	if len(res) == 0 {
+		return nil, errUnrecognizedLicense
+	}
+	return res, nil
+}
+
 func prepareManualLicense(vendorDir string) (map[string]string, error) {
 	fileName := filepath.Join(vendorDir, "manualLicense.json")
 	log.Println("Processing manual license file: ", fileName)
@@ -267,18 +749,15 @@ func prepareManualLicense(vendorDir string) (map[string]string, error) {
 }
 
 // parseLicenseManual will look for the manual license file index, to add files that cannot be found automatically
-func parseLicenseManual(dir string, manualFileMap map[string]string) (lDir string, lContent string, missing bool) {
-	dirs := strings.Split(dir, "/")
-	currentDir := ""
+func parseLicenseManual(dep dependency, manualFileMap map[string]string) (lDir string, lContent string, missing bool) {
 	missing = true
-	lDir = dir
-	for i := range dirs {
-		currentDir = filepath.Join(currentDir, dirs[i])
-		content, exists := manualFileMap[currentDir]
+	lDir = dep.Name
+	for _, key := range dep.ManualKeys {
+		content, exists := manualFileMap[key]
 		if exists {
 			missing = false
 			lContent = content
-			lDir = currentDir
+			lDir = key
 			break
 		}
 	}
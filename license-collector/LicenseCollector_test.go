@@ -0,0 +1,61 @@
+package licensecollector
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsLicenseFile(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"LICENSE", true},
+		{"license.md", true},
+		{"COPYING", true},
+		{"UNLICENSE", true},
+		{"LICENSE-MIT", true},
+		{"LICENSE-APACHE", true},
+		{"LICENSE-APACHE-2.0", true},
+		{"license_mit.txt", true},
+		{"Licence-BSD.rst", true},
+		{"LICENSE.go", false},
+		{"license_test.go", false},
+		{"license_windows.go", false},
+		{"license-checker.js", false},
+		{"licenses.json", false},
+		{"LICENSE-", false},
+		{"README.md", false},
+	}
+	for _, tt := range tests {
+		if got := isLicenseFile(tt.name); got != tt.want {
+			t.Errorf("isLicenseFile(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLicensesFromFS(t *testing.T) {
+	dir := t.TempDir()
+	paragraphs := strings.Split(testMITText, "\n\n")
+	paragraphs[2], paragraphs[3] = paragraphs[3], paragraphs[2]
+	files := map[string]string{
+		"LICENSE":     testMITText,
+		"LICENSE-MIT": strings.Join(paragraphs, "\n\n"),
+		"license.go":  testMITText,
+	}
+	writeFiles(t, dir, files)
+	licenses, err := licensesFromFS(dirFS(dir), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(licenses) != 2 {
+		t.Fatalf("licensesFromFS() = %d licenses, want 2", len(licenses))
+	}
+	if l := licenses[0]; l.File != "LICENSE" || l.ID != "MIT" || l.Evidence != evidenceExactText || l.Confidence != 100 {
+		t.Errorf("licensesFromFS()[0] = %s %s %s %v, want an exact MIT match", l.File, l.ID, l.Evidence, l.Confidence)
+	}
+	// the paragraphs out of order are the same words, not the same text
+	if l := licenses[1]; l.File != "LICENSE-MIT" || l.ID != "MIT" || l.Evidence != evidenceFuzzyText || l.Confidence >= 100 {
+		t.Errorf("licensesFromFS()[1] = %s %s %s %v, want a fuzzy MIT match", l.File, l.ID, l.Evidence, l.Confidence)
+	}
+}
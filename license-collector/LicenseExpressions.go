@@ -0,0 +1,285 @@
+package licensecollector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SPDX license expression operators, by increasing precedence
+const (
+	licenseOr   = "OR"
+	licenseAnd  = "AND"
+	licenseWith = "WITH"
+)
+
+// licenseExpression is a parsed SPDX license expression, e.g.
+// "(MIT OR Apache-2.0) AND GPL-2.0-only WITH Classpath-exception-2.0"
+type licenseExpression struct {
+	// Op is OR or AND for compound expressions, empty for a single license
+	Op   string
+	Args []*licenseExpression
+	// License is the license identifier of a single license
+	License string
+	// OrLater is set by the "+" suffix of License
+	OrLater bool
+	// Exception is the WITH exception of a single license
+	Exception string
+}
+
+// tokenizeLicenseExpression splits an expression into parentheses and words
+func tokenizeLicenseExpression(s string) []string {
+	return strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(s))
+}
+
+// licenseExpressionParser is a recursive descent parser of SPDX license expressions
+type licenseExpressionParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *licenseExpressionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *licenseExpressionParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+// isOperator checks if a token is an operator, upper or lower case as the specification allows
+func isOperator(token string, op string) bool {
+	return token == op || token == strings.ToLower(op)
+}
+
+// parseCompound parses the operands of op, each of them parsed by operand
+func (p *licenseExpressionParser) parseCompound(op string, operand func() (*licenseExpression, error)) (*licenseExpression, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	args := []*licenseExpression{first}
+	for isOperator(p.peek(), op) {
+		p.next()
+		arg, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	if len(args) == 1 {
+		return first, nil
+	}
+	return &licenseExpression{Op: op, Args: args}, nil
+}
+
+func (p *licenseExpressionParser) parseOr() (*licenseExpression, error) {
+	return p.parseCompound(licenseOr, p.parseAnd)
+}
+
+func (p *licenseExpressionParser) parseAnd() (*licenseExpression, error) {
+	return p.parseCompound(licenseAnd, p.parseLicense)
+}
+
+// parseLicense parses a parenthesized expression, or a license with its exception
+func (p *licenseExpressionParser) parseLicense() (*licenseExpression, error) {
+	token := p.next()
+	switch {
+	case len(token) == 0:
+		return nil, fmt.Errorf("unexpected end of expression")
+	case token == "(":
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		return e, nil
+	case token == ")" || isOperator(token, licenseOr) || isOperator(token, licenseAnd) || isOperator(token, licenseWith):
+		return nil, fmt.Errorf("unexpected %s", token)
+	}
+	e := &licenseExpression{License: token}
+	if strings.HasSuffix(token, "+") {
+		e.License = strings.TrimSuffix(token, "+")
+		e.OrLater = true
+	}
+	if isOperator(p.peek(), licenseWith) {
+		p.next()
+		e.Exception = p.next()
+		if len(e.Exception) == 0 || e.Exception == "(" || e.Exception == ")" {
+			return nil, fmt.Errorf("missing exception after %s", licenseWith)
+		}
+	}
+	return e, nil
+}
+
+// parseLicenseExpression parses an SPDX license expression. Identifiers are not validated.
+func parseLicenseExpression(s string) (*licenseExpression, error) {
+	p := &licenseExpressionParser{tokens: tokenizeLicenseExpression(s)}
+	e, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %s", p.peek())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid license expression %q: %s", s, err)
+	}
+	return e, nil
+}
+
+// isLicenseRef checks if a license identifier is a user defined reference, not in the SPDX license list
+func isLicenseRef(id string) bool {
+	return strings.HasPrefix(id, "LicenseRef-") || strings.HasPrefix(id, "DocumentRef-")
+}
+
+// spdxExceptionByID finds an exception of the SPDX license list, case insensitive
+func spdxExceptionByID(id string) (spdxLicense, bool) {
+	if e, ok := spdxExceptions[id]; ok {
+		return e, true
+	}
+	for spdxID, e := range spdxExceptions {
+		if strings.EqualFold(spdxID, id) {
+			return e, true
+		}
+	}
+	return spdxLicense{}, false
+}
+
+// validate checks that the licenses and exceptions are in the SPDX license list, and
+// gives them their canonical identifier: aliases and deprecated identifiers are replaced
+func (e *licenseExpression) validate() error {
+	if len(e.Op) > 0 {
+		for _, arg := range e.Args {
+			if err := arg.validate(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	id := e.License
+	if e.OrLater {
+		id += "+"
+	}
+	if alias, ok := licenseAlias(id); ok {
+		if a, err := parseLicenseExpression(alias); err == nil && len(a.Op) == 0 {
+			e.License, e.OrLater = a.License, a.OrLater
+			if len(e.Exception) == 0 {
+				e.Exception = a.Exception
+			}
+		}
+	}
+	if !isLicenseRef(e.License) {
+		l, ok := spdxLicenseByID(e.License)
+		if !ok {
+			return fmt.Errorf("unknown license %s", e.License)
+		}
+		e.License = l.ID
+	}
+	if len(e.Exception) > 0 {
+		exception, ok := spdxExceptionByID(e.Exception)
+		if !ok {
+			return fmt.Errorf("unknown license exception %s", e.Exception)
+		}
+		e.Exception = exception.ID
+	}
+	return nil
+}
+
+// String prints the expression canonically: upper case operators, nested operands of the
+// same operator flattened, and parentheses only where precedence needs them
+func (e *licenseExpression) String() string {
+	if len(e.Op) == 0 {
+		res := e.License
+		if e.OrLater {
+			res += "+"
+		}
+		if len(e.Exception) > 0 {
+			res += " " + licenseWith + " " + e.Exception
+		}
+		return res
+	}
+	var args []string
+	for _, arg := range e.flatten() {
+		s := arg.String()
+		if e.Op == licenseAnd && arg.Op == licenseOr {
+			s = "(" + s + ")"
+		}
+		args = append(args, s)
+	}
+	return strings.Join(args, " "+e.Op+" ")
+}
+
+// flatten returns the operands of a compound expression, the operands of nested
+// expressions with the same operator included
+func (e *licenseExpression) flatten() []*licenseExpression {
+	var res []*licenseExpression
+	for _, arg := range e.Args {
+		if arg.Op == e.Op {
+			res = append(res, arg.flatten()...)
+		} else {
+			res = append(res, arg)
+		}
+	}
+	return res
+}
+
+// licenses returns the single licenses of the expression, in order
+func (e *licenseExpression) licenses() []*licenseExpression {
+	if len(e.Op) == 0 {
+		return []*licenseExpression{e}
+	}
+	var res []*licenseExpression
+	for _, arg := range e.Args {
+		res = append(res, arg.licenses()...)
+	}
+	return res
+}
+
+// rank is the preference of the expression: the rank of its least preferred license, every
+// license of it has to be complied with. Licenses missing from preferred rank last.
+func (e *licenseExpression) rank(preferred []string) int {
+	res := 0
+	for _, l := range e.licenses() {
+		r := len(preferred)
+		for i, p := range preferred {
+			if p = normalizeLicenseID(strings.TrimSpace(p)); strings.EqualFold(p, l.License) || strings.EqualFold(p, l.String()) {
+				r = i
+				break
+			}
+		}
+		if r > res {
+			res = r
+		}
+	}
+	return res
+}
+
+// choose picks the branch of every OR that is complied under: the most preferred one. An OR
+// is kept as is when none of its branches has only preferred licenses.
+func (e *licenseExpression) choose(preferred []string) *licenseExpression {
+	switch e.Op {
+	case licenseAnd:
+		res := &licenseExpression{Op: e.Op}
+		for _, arg := range e.Args {
+			res.Args = append(res.Args, arg.choose(preferred))
+		}
+		return res
+	case licenseOr:
+		var best *licenseExpression
+		bestRank := len(preferred)
+		for _, arg := range e.Args {
+			c := arg.choose(preferred)
+			if r := c.rank(preferred); r < bestRank {
+				best, bestRank = c, r
+			}
+		}
+		if best == nil {
+			return e
+		}
+		return best
+	}
+	return e
+}
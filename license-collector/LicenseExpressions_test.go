@@ -0,0 +1,138 @@
+package licensecollector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLicenseExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       string
+		wantErr    bool
+	}{
+		{expression: "MIT", want: "MIT"},
+		{expression: "(MIT)", want: "MIT"},
+		{expression: "mit or apache-2.0", want: "mit OR apache-2.0"},
+		{expression: "GPL-2.0+", want: "GPL-2.0+"},
+		{expression: "(MIT OR Apache-2.0) AND GPL-2.0-only WITH Classpath-exception-2.0", want: "(MIT OR Apache-2.0) AND GPL-2.0-only WITH Classpath-exception-2.0"},
+		{expression: "MIT OR Apache-2.0 AND ISC", want: "MIT OR Apache-2.0 AND ISC"},
+		{expression: "MIT AND (BSD-2-Clause AND ISC)", want: "MIT AND BSD-2-Clause AND ISC"},
+		{expression: "((MIT OR ISC) OR 0BSD)", want: "MIT OR ISC OR 0BSD"},
+		{expression: "", wantErr: true},
+		{expression: "MIT OR", wantErr: true},
+		{expression: "(MIT", wantErr: true},
+		{expression: "MIT)", wantErr: true},
+		{expression: "AND MIT", wantErr: true},
+		{expression: "MIT WITH", wantErr: true},
+		{expression: "MIT WITH (ISC)", wantErr: true},
+		{expression: "MIT Apache-2.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		e, err := parseLicenseExpression(tt.expression)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseLicenseExpression(%q) error = %v, wantErr %v", tt.expression, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && e.String() != tt.want {
+			t.Errorf("parseLicenseExpression(%q) = %s, want %s", tt.expression, e, tt.want)
+		}
+	}
+}
+
+func TestParseLicenseExpressionPrecedence(t *testing.T) {
+	e, err := parseLicenseExpression("MIT OR Apache-2.0 AND GPL-2.0-only WITH Classpath-exception-2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &licenseExpression{Op: licenseOr, Args: []*licenseExpression{
+		{License: "MIT"},
+		{Op: licenseAnd, Args: []*licenseExpression{
+			{License: "Apache-2.0"},
+			{License: "GPL-2.0-only", Exception: "Classpath-exception-2.0"},
+		}},
+	}}
+	if !reflect.DeepEqual(e, want) {
+		t.Errorf("parseLicenseExpression() = %+v, want %+v", e, want)
+	}
+}
+
+func TestLicenseExpressionValidate(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       string
+		wantErr    bool
+	}{
+		{expression: "mit or apache-2.0", want: "MIT OR Apache-2.0"},
+		{expression: "GPL-2.0", want: "GPL-2.0-only"},
+		{expression: "GPL-2.0+", want: "GPL-2.0-or-later"},
+		{expression: "gpl-2.0 with classpath-exception-2.0", want: "GPL-2.0-only WITH Classpath-exception-2.0"},
+		{expression: "GPL-2.0-with-classpath-exception", want: "GPL-2.0-only WITH Classpath-exception-2.0"},
+		{expression: "LicenseRef-Acme AND MIT", want: "LicenseRef-Acme AND MIT"},
+		{expression: "Acme-1.0", wantErr: true},
+		{expression: "MIT WITH Acme-exception", wantErr: true},
+	}
+	for _, tt := range tests {
+		e, err := parseLicenseExpression(tt.expression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = e.validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validate(%q) error = %v, wantErr %v", tt.expression, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && e.String() != tt.want {
+			t.Errorf("validate(%q) = %s, want %s", tt.expression, e, tt.want)
+		}
+	}
+}
+
+func TestLicenseExpressionRank(t *testing.T) {
+	preferred := []string{"MIT", "Apache-2.0", "GPL-2.0-only WITH Classpath-exception-2.0"}
+	tests := []struct {
+		expression string
+		want       int
+	}{
+		{"MIT", 0},
+		{"MIT AND Apache-2.0", 1},
+		{"GPL-2.0-only WITH Classpath-exception-2.0", 2},
+		{"GPL-2.0-only", 3},
+		{"MIT AND ISC", 3},
+	}
+	for _, tt := range tests {
+		e, err := parseLicenseExpression(tt.expression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := e.rank(preferred); got != tt.want {
+			t.Errorf("rank(%q) = %d, want %d", tt.expression, got, tt.want)
+		}
+	}
+}
+
+func TestLicenseExpressionChoose(t *testing.T) {
+	tests := []struct {
+		expression string
+		preferred  []string
+		want       string
+	}{
+		{"MIT OR GPL-3.0-only", []string{"MIT"}, "MIT"},
+		{"GPL-3.0-only OR MIT", []string{"Apache-2.0", "MIT"}, "MIT"},
+		{"MIT OR Apache-2.0", []string{"Apache-2.0", "MIT"}, "Apache-2.0"},
+		{"(GPL-2.0-only OR MIT) AND (Apache-2.0 OR GPL-3.0-only)", []string{"Apache-2.0", "MIT"}, "MIT AND Apache-2.0"},
+		{"(MIT AND ISC) OR Apache-2.0", []string{"MIT", "ISC"}, "MIT AND ISC"},
+		// no branch has only preferred licenses
+		{"GPL-2.0-only OR GPL-3.0-only", []string{"MIT"}, "GPL-2.0-only OR GPL-3.0-only"},
+		{"MIT OR Apache-2.0", nil, "MIT OR Apache-2.0"},
+	}
+	for _, tt := range tests {
+		e, err := parseLicenseExpression(tt.expression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := e.choose(tt.preferred).String(); got != tt.want {
+			t.Errorf("choose(%q, %v) = %s, want %s", tt.expression, tt.preferred, got, tt.want)
+		}
+	}
+}
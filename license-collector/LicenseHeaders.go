@@ -0,0 +1,127 @@
+package licensecollector
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// SPDX-License-Identifier headers are weaker evidence than license files
+const (
+	// licenseHeaderConfidence is the confidence of a license declared by every sampled source file
+	licenseHeaderConfidence = 50.0
+	// licenseHeaderSamples is the number of source files sampled per package
+	licenseHeaderSamples = 100
+	// licenseHeaderLines is the number of lines of the top of a file searched for the header
+	licenseHeaderLines = 30
+)
+
+// licenseHeader matches an SPDX-License-Identifier header, in any comment style
+var licenseHeader = regexp.MustCompile(`SPDX-License-Identifier:\s*(.*?)\s*(?:\*/|-->|#\}|$)`)
+
+// licenseHeaderExtensions are the extensions of the source files sampled for headers
+var licenseHeaderExtensions = []string{
+	".go", ".s", ".c", ".h", ".cc", ".cpp", ".hpp", ".rs", ".py", ".java", ".kt", ".swift",
+	".js", ".mjs", ".cjs", ".jsx", ".ts", ".mts", ".cts", ".tsx", ".vue", ".css", ".scss",
+}
+
+// isSourceDir checks if a directory of a package may hold its own sources: dependencies,
+// tests, hidden and ignored directories do not
+func isSourceDir(name string) bool {
+	return name != nodeModules && name != "vendor" && name != "testdata" && !strings.HasPrefix(name, ".") && !strings.HasPrefix(name, "_")
+}
+
+// sampleSourceFiles returns up to max source files of a package directory, breadth first.
+// Nested go modules, the directories with a go.mod of their own, are other packages.
+func sampleSourceFiles(fsys sourceFS, dir string, max int) []string {
+	var res []string
+	queue := []string{dir}
+	for len(queue) > 0 && len(res) < max {
+		current := queue[0]
+		queue = queue[1:]
+		entries, err := fsys.ReadDir(current)
+		if err != nil || (current != dir && InStringSlice(dirEntryNames(entries), goModFile)) {
+			continue
+		}
+		for _, e := range entries {
+			p := path.Join(current, e.Name)
+			if e.IsDir {
+				if isSourceDir(e.Name) {
+					queue = append(queue, p)
+				}
+				continue
+			}
+			ext := strings.ToLower(path.Ext(e.Name))
+			if InStringSlice(licenseHeaderExtensions, ext) && !strings.HasSuffix(e.Name, ".d.ts") && len(res) < max {
+				res = append(res, p)
+			}
+		}
+	}
+	return res
+}
+
+// readLicenseHeader returns the SPDX-License-Identifier of the top of a source file
+func readLicenseHeader(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for i := 0; i < licenseHeaderLines && scanner.Scan(); i++ {
+		if m := licenseHeader.FindStringSubmatch(scanner.Text()); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// scanLicenseHeaders adds up the SPDX-License-Identifier headers of sampled source files of a
+// package without license files. The result is every declared expression joined with AND,
+// with a license "file" per license: the first source file declaring it, and a confidence
+// proportional to the share of the sampled files declaring it.
+func scanLicenseHeaders(fsys sourceFS, dir string) (*licenseExpression, []licenseFile) {
+	files := sampleSourceFiles(fsys, dir, licenseHeaderSamples)
+	if len(files) == 0 {
+		return nil, nil
+	}
+	var expressions []string
+	byExpression := map[string]*licenseExpression{}
+	counts := map[string]int{}
+	firstFile := map[string]string{}
+	for _, file := range files {
+		data, err := fsys.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		header := readLicenseHeader(data)
+		if len(header) == 0 {
+			continue
+		}
+		expression, ok := knownLicenseExpression(header)
+		if !ok {
+			continue
+		}
+		key := expression.String()
+		if _, ok := byExpression[key]; !ok {
+			expressions = append(expressions, key)
+			byExpression[key] = expression
+			firstFile[key] = file
+		}
+		counts[key]++
+	}
+	if len(expressions) == 0 {
+		return nil, nil
+	}
+	res := &licenseExpression{Op: licenseAnd}
+	var licenses []licenseFile
+	for _, key := range expressions {
+		res.Args = append(res.Args, byExpression[key])
+		confidence := math.Round(10*licenseHeaderConfidence*float64(counts[key])/float64(len(files))) / 10
+		for _, l := range byExpression[key].licenses() {
+			licenses = append(licenses, licenseFile{File: firstFile[key], licenseMatch: licenseMatch{ID: l.License, Confidence: confidence}, Evidence: evidenceHeader})
+		}
+	}
+	if len(res.Args) == 1 {
+		return res.Args[0], licenses
+	}
+	return res, licenses
+}
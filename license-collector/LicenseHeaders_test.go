@@ -0,0 +1,92 @@
+package licensecollector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadLicenseHeader(t *testing.T) {
+	tests := []struct{ data, want string }{
+		{"// SPDX-License-Identifier: MIT\npackage a\n", "MIT"},
+		{"#!/usr/bin/env python\n# SPDX-License-Identifier: Apache-2.0\n", "Apache-2.0"},
+		{"/* SPDX-License-Identifier: MIT OR Apache-2.0 */\n", "MIT OR Apache-2.0"},
+		{"<!-- SPDX-License-Identifier: MIT -->\n", "MIT"},
+		{strings.Repeat("\n", licenseHeaderLines) + "// SPDX-License-Identifier: MIT\n", ""},
+		{"package a\n", ""},
+	}
+	for _, tt := range tests {
+		if got := readLicenseHeader([]byte(tt.data)); got != tt.want {
+			t.Errorf("readLicenseHeader(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestScanLicenseHeaders(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.go":                    "// SPDX-License-Identifier: MIT\npackage a\n",
+		"b.go":                    "// SPDX-License-Identifier: MIT\npackage a\n",
+		"c.go":                    "package a\n",
+		"internal/d.go":           "/* SPDX-License-Identifier: Apache-2.0 */\npackage internal\n",
+		"lib.v2/g.go":             "package lib\n",
+		"types.d.ts":              "// SPDX-License-Identifier: ISC\n",
+		"README.md":               "SPDX-License-Identifier: ISC\n",
+		"testdata/e.go":           "// SPDX-License-Identifier: ISC\n",
+		"node_modules/x/index.js": "// SPDX-License-Identifier: ISC\n",
+		// a nested module is a package of its own
+		"sub/go.mod": "module example.com/a/sub\n",
+		"sub/f.go":   "// SPDX-License-Identifier: GPL-2.0-only\npackage sub\n",
+	}
+	writeFiles(t, dir, files)
+	fsys := dirFS(dir)
+	wantFiles := []string{"a.go", "b.go", "c.go", "internal/d.go", "lib.v2/g.go"}
+	if got := sampleSourceFiles(fsys, "", licenseHeaderSamples); !reflect.DeepEqual(got, wantFiles) {
+		t.Errorf("sampleSourceFiles() = %v, want %v", got, wantFiles)
+	}
+	if got := sampleSourceFiles(fsys, "", 2); !reflect.DeepEqual(got, wantFiles[:2]) {
+		t.Errorf("sampleSourceFiles() with 2 samples = %v, want %v", got, wantFiles[:2])
+	}
+
+	expression, licenses := scanLicenseHeaders(fsys, "")
+	if expression == nil || expression.String() != "MIT AND Apache-2.0" {
+		t.Fatalf("scanLicenseHeaders() = %v, want MIT AND Apache-2.0", expression)
+	}
+	wantLicenses := []licenseFile{
+		{File: "a.go", licenseMatch: licenseMatch{ID: "MIT", Confidence: 20}, Evidence: evidenceHeader},
+		{File: "internal/d.go", licenseMatch: licenseMatch{ID: "Apache-2.0", Confidence: 10}, Evidence: evidenceHeader},
+	}
+	if !reflect.DeepEqual(licenses, wantLicenses) {
+		t.Errorf("scanLicenseHeaders() licenses = %+v, want %+v", licenses, wantLicenses)
+	}
+}
+
+func TestDeclaredLicenseBeforeHeaders(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"index.js": "// SPDX-License-Identifier: MIT\n",
+		"lib.js":   "module.exports = {}\n",
+	})
+	tests := []struct {
+		name         string
+		declared     string
+		wantEvidence string
+		wantConf     float64
+	}{
+		// the package metadata is stronger evidence than the headers of some of the sources
+		{name: "declared", declared: "MIT", wantEvidence: evidenceMetadata, wantConf: 60},
+		{name: "headers", wantEvidence: evidenceHeader, wantConf: 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collection := &licenseCollection{}
+			doParseFile(dirFS(dir), dependency{Name: "foo", LicenseDirs: []string{""}, Declared: tt.declared}, nil, collection)
+			if len(collection.entries) != 1 {
+				t.Fatalf("doParseFile() entries = %+v, want one", collection.entries)
+			}
+			if e := collection.entries[0]; e.Type != "MIT" || e.Evidence != tt.wantEvidence || e.Confidence != tt.wantConf {
+				t.Errorf("doParseFile() = %s %s %v, want MIT %s %v", e.Type, e.Evidence, e.Confidence, tt.wantEvidence, tt.wantConf)
+			}
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package licensecollector
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// minLicenseConfidence is the lowest match confidence, in percent, of a detected license
+const minLicenseConfidence = 75.0
+
+// ambiguousLicenseMargin is the score difference, in percent, under which the two best
+// matching licenses are not told apart
+const ambiguousLicenseMargin = 2.0
+
+// ambiguousLicenseConfidence is the confidence of the best license of an ambiguous match
+const ambiguousLicenseConfidence = 50.0
+
+var errUnrecognizedLicense = errors.New("license: could not guess license type")
+
+// licenseMatch is the result of matching a license text against the SPDX license list
+type licenseMatch struct {
+	// ID is the SPDX identifier of the best matching license
+	ID string
+	// Confidence is the match score in percent
+	Confidence float64
+	// Exact is set when the text is the license template word for word
+	Exact bool
+}
+
+// licenseShingleSize is the number of consecutive words compared as one: word order
+// tells apart licenses made of the same words
+const licenseShingleSize = 3
+
+// licenseTemplate is an SPDX license template prepared for matching
+type licenseTemplate struct {
+	id string
+	// required are the shingles every copy of the license has
+	required map[string]int
+	// requiredCount is the number of required shingles
+	requiredCount int
+	// allowed are the shingles a copy may have, optional and replaceable parts included
+	allowed map[string]int
+	// wordCount is the number of words of the template, optional parts included
+	wordCount int
+	// pattern matches the normalized words of an exact copy, compiled on first use
+	pattern     string
+	patternOnce sync.Once
+	patternRE   *regexp.Regexp
+}
+
+var (
+	licenseTemplatesOnce sync.Once
+	licenseTemplates     []*licenseTemplate
+)
+
+// equivalentWords are the spelling variants the SPDX matching guidelines consider equal
+var equivalentWords = map[string]string{
+	"acknowledgement": "acknowledgment",
+	"analogue":        "analog",
+	"analyse":         "analyze",
+	"artefact":        "artifact",
+	"authorisation":   "authorization",
+	"authorised":      "authorized",
+	"calibre":         "caliber",
+	"cancelled":       "canceled",
+	"capitalisations": "capitalizations",
+	"catalogue":       "catalog",
+	"categorise":      "categorize",
+	"centre":          "center",
+	"emphasised":      "emphasized",
+	"favour":          "favor",
+	"favourite":       "favorite",
+	"fulfil":          "fulfill",
+	"fulfilment":      "fulfillment",
+	"initialise":      "initialize",
+	"judgement":       "judgment",
+	"labelling":       "labeling",
+	"labour":          "labor",
+	"licence":         "license",
+	"licences":        "licenses",
+	"licenced":        "licensed",
+	"maximise":        "maximize",
+	"modelled":        "modeled",
+	"modelling":       "modeling",
+	"offence":         "offense",
+	"optimise":        "optimize",
+	"organisation":    "organization",
+	"organise":        "organize",
+	"practise":        "practice",
+	"programme":       "program",
+	"realise":         "realize",
+	"recognise":       "recognize",
+	"signalling":      "signaling",
+	"utilisation":     "utilization",
+	"whilst":          "while",
+	"wilful":          "willful",
+	"https":           "http",
+}
+
+var (
+	// copyrightLine matches copyright notices, which are not part of the license
+	copyrightLine = regexp.MustCompile(`(?i)^\s*(copyright\s*(\(c\)|©|\d{4}|by\b)|©|\(c\)\s*\d{4}|all rights reserved)`)
+	// bulletPrefix matches list markers: "1.", "1.1", "(a)", "iv)", "*", "-"
+	bulletPrefix = regexp.MustCompile(`^\s*(\d+(\.\d+)*\.?|\(?[a-z0-9]{1,4}\)|[a-z]\.|[*•\-–—])\s+`)
+	// wordSeparator splits words, hyphens join the parts of a word
+	wordSeparator = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// normalizeLicenseWords normalizes a license text following the SPDX matching guidelines:
+// case, whitespace, punctuation, bullets, copyright notices and spelling variants do not matter
+func normalizeLicenseWords(text string, skipCopyright bool) []string {
+	var words []string
+	for _, line := range strings.Split(text, "\n") {
+		if skipCopyright && copyrightLine.MatchString(line) {
+			continue
+		}
+		words = append(words, normalizeLicenseLine(line, true)...)
+	}
+	return words
+}
+
+// normalizeLicenseLine returns the normalized words of a line, or of a part of it.
+// Bullets only start a line.
+func normalizeLicenseLine(line string, lineStart bool) []string {
+	var words []string
+	line = strings.NewReplacer("&", " and ", "-", "", "‐", "", "‑", "").Replace(strings.ToLower(line))
+	if lineStart {
+		line = bulletPrefix.ReplaceAllString(line, "")
+	}
+	for _, word := range wordSeparator.Split(line, -1) {
+		if len(word) == 0 {
+			continue
+		}
+		if equivalent, ok := equivalentWords[word]; ok {
+			word = equivalent
+		}
+		words = append(words, word)
+	}
+	return words
+}
+
+// shingles counts the runs of licenseShingleSize consecutive words. Empty words are
+// gaps no run spans.
+func shingles(words []string) (map[string]int, int) {
+	res := map[string]int{}
+	count := 0
+	for i := 0; i+licenseShingleSize <= len(words); i++ {
+		run := words[i : i+licenseShingleSize]
+		if InStringSlice(run, "") {
+			continue
+		}
+		res[strings.Join(run, " ")]++
+		count++
+	}
+	return res, count
+}
+
+// kinds of the parts of a license template
+const (
+	templateText = iota
+	templateNewline
+	templateBeginOptional
+	templateEndOptional
+	templateVar
+)
+
+// maxCopyrightLine is the length of the longest copyright notice line of a template
+const maxCopyrightLine = 100
+
+// templatePart is a line of text, a line break or a tag of a license template. The
+// text of a var part is its original text.
+type templatePart struct {
+	kind int
+	text string
+	// copyright is set on the parts of a copyright notice line
+	copyright bool
+}
+
+// splitLicenseTemplate splits an SPDX license template into its parts, and marks the
+// copyright notice lines, skipped like those of the license texts
+func splitLicenseTemplate(template string) []templatePart {
+	var parts []templatePart
+	addText := func(text string) {
+		for i, line := range strings.Split(text, "\n") {
+			if i > 0 {
+				parts = append(parts, templatePart{kind: templateNewline})
+			}
+			if len(line) > 0 {
+				parts = append(parts, templatePart{kind: templateText, text: line})
+			}
+		}
+	}
+	for len(template) > 0 {
+		i := strings.Index(template, "<<")
+		if i < 0 {
+			i = len(template)
+		}
+		addText(template[:i])
+		template = template[i:]
+		end := strings.Index(template, ">>")
+		if end < 0 {
+			break
+		}
+		switch tag := template[:end]; {
+		case strings.HasPrefix(tag, "<<beginOptional"):
+			parts = append(parts, templatePart{kind: templateBeginOptional})
+		case strings.HasPrefix(tag, "<<endOptional"):
+			parts = append(parts, templatePart{kind: templateEndOptional})
+		case strings.HasPrefix(tag, "<<var;"):
+			// the attributes are quoted, and may hold ">>"
+			if end = strings.Index(template, `">>`); end < 0 {
+				return parts
+			}
+			end++
+			part := templatePart{kind: templateVar}
+			if j := strings.Index(template[:end], `original="`); j >= 0 {
+				original := template[j+len(`original="`) : end]
+				if k := strings.Index(original, `";`); k >= 0 {
+					original = original[:k]
+				}
+				part.text = strings.TrimSuffix(original, `"`)
+			}
+			parts = append(parts, part)
+		}
+		template = template[end+2:]
+	}
+
+	start := 0
+	for i := 0; i <= len(parts); i++ {
+		if i < len(parts) && parts[i].kind != templateNewline {
+			continue
+		}
+		var line strings.Builder
+		for _, part := range parts[start:i] {
+			line.WriteString(part.text)
+		}
+		// license texts wrap lines, a paragraph starting with a copyright notice is not one
+		if text := strings.TrimSpace(line.String()); len(text) <= maxCopyrightLine && copyrightLine.MatchString(text) {
+			for j := start; j < i; j++ {
+				parts[j].copyright = true
+			}
+		}
+		start = i + 1
+	}
+	return parts
+}
+
+// parseLicenseTemplate prepares an SPDX license template: its required words, the words
+// of its optional (<<beginOptional>>) and replaceable (<<var>>) parts, and their order
+func parseLicenseTemplate(id string, template string) *licenseTemplate {
+	// all are the words of the template as written, required skips the optional parts
+	// and leaves a gap for every replaceable one
+	var all, required []string
+	var pattern strings.Builder
+	depth := 0
+	lineStart := true
+	for _, part := range splitLicenseTemplate(template) {
+		switch part.kind {
+		case templateNewline:
+			lineStart = true
+		case templateBeginOptional:
+			depth++
+			pattern.WriteString("(?:")
+		case templateEndOptional:
+			if depth > 0 {
+				depth--
+				pattern.WriteString(")?")
+			}
+		case templateText:
+			if part.copyright {
+				continue
+			}
+			for _, word := range normalizeLicenseLine(part.text, lineStart) {
+				all = append(all, word)
+				if depth == 0 {
+					required = append(required, word)
+				}
+				pattern.WriteString(" " + word)
+			}
+			lineStart = lineStart && len(strings.TrimSpace(part.text)) == 0
+		case templateVar:
+			if part.copyright {
+				continue
+			}
+			// a bullet is only known as such followed by a space
+			original := normalizeLicenseLine(part.text+" ", lineStart)
+			all = append(all, original...)
+			if depth == 0 && len(original) > 0 {
+				required = append(required, "")
+			}
+			// any words replace the original
+			pattern.WriteString("(?: [a-z0-9]+)*?")
+			lineStart = false
+		}
+	}
+	for ; depth > 0; depth-- {
+		pattern.WriteString(")?")
+	}
+
+	t := &licenseTemplate{id: id, wordCount: len(all), pattern: "^" + pattern.String() + "$"}
+	t.required, t.requiredCount = shingles(required)
+	// a copy has the shingles of the template as written, or the required ones across
+	// the optional parts it leaves out
+	t.allowed, _ = shingles(all)
+	for s, n := range t.required {
+		if n > t.allowed[s] {
+			t.allowed[s] = n
+		}
+	}
+	return t
+}
+
+// loadLicenseTemplates prepares the templates of the SPDX license list, deprecated identifiers
+// excluded. Identical templates are kept once, under the first identifier. The templates of
+// "-only" and "-or-later" versions differ in their optional parts, both are kept.
+func loadLicenseTemplates() []*licenseTemplate {
+	licenseTemplatesOnce.Do(func() {
+		var ids []string
+		for id, l := range spdxLicenses {
+			if !l.Deprecated {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		seen := map[string]struct{}{}
+		for _, id := range ids {
+			l := spdxLicenses[id]
+			template := l.Template
+			if len(template) == 0 {
+				template = l.Text
+			}
+			t := parseLicenseTemplate(id, template)
+			if _, ok := seen[t.pattern]; ok || t.requiredCount == 0 {
+				continue
+			}
+			seen[t.pattern] = struct{}{}
+			licenseTemplates = append(licenseTemplates, t)
+		}
+	})
+	return licenseTemplates
+}
+
+// score compares the shingles of a text to the template: the harmonic mean of the share
+// of the template found in the text, and the share of the text the template explains
+func (t *licenseTemplate) score(textShingles map[string]int, count int) float64 {
+	matched := 0
+	explained := 0
+	for s, n := range textShingles {
+		if r := t.required[s]; r < n {
+			matched += r
+		} else {
+			matched += n
+		}
+		if a := t.allowed[s]; a < n {
+			explained += a
+		} else {
+			explained += n
+		}
+	}
+	if matched == 0 {
+		return 0
+	}
+	recall := float64(matched) / float64(t.requiredCount)
+	precision := float64(explained) / float64(count)
+	// one decimal is enough to compare matches
+	return math.Round(2000*recall*precision/(recall+precision)) / 10
+}
+
+// matches checks if the normalized words of a text are a copy of the template, in order
+func (t *licenseTemplate) matches(words []string) bool {
+	t.patternOnce.Do(func() {
+		t.patternRE, _ = regexp.Compile(t.pattern)
+	})
+	return t.patternRE != nil && t.patternRE.MatchString(" "+strings.Join(words, " "))
+}
+
+// matchLicense finds the SPDX license a text is a copy of. A text scoring about as well
+// against two licenses, which are not copied word for word, matches the best of them
+// with ambiguousLicenseConfidence. Versions of the same text, e.g. GPL-2.0-only and
+// GPL-2.0-or-later, are one license.
+func matchLicense(text string) (licenseMatch, error) {
+	words := normalizeLicenseWords(text, true)
+	textShingles, count := shingles(words)
+	if count == 0 {
+		return licenseMatch{}, errUnrecognizedLicense
+	}
+	type candidate struct {
+		t     *licenseTemplate
+		score float64
+	}
+	var candidates []candidate
+	for _, t := range loadLicenseTemplates() {
+		// a text far shorter or longer than the license cannot be a copy of it
+		if t.requiredCount > 3*count || len(words) > 3*t.wordCount+50 {
+			continue
+		}
+		if score := t.score(textShingles, count); score > 0 {
+			candidates = append(candidates, candidate{t, score})
+		}
+	}
+	if len(candidates) == 0 {
+		return licenseMatch{}, errUnrecognizedLicense
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+	best := candidates[0]
+	for _, c := range candidates {
+		if best.score-c.score > ambiguousLicenseMargin {
+			break
+		}
+		if c.t.matches(words) {
+			return licenseMatch{ID: c.t.id, Confidence: 100, Exact: true}, nil
+		}
+	}
+	// only an exact copy is certain
+	res := licenseMatch{ID: best.t.id, Confidence: math.Min(best.score, 99.9)}
+	if res.Confidence < minLicenseConfidence {
+		return res, errUnrecognizedLicense
+	}
+	for _, c := range candidates[1:] {
+		if best.score-c.score > ambiguousLicenseMargin {
+			break
+		}
+		if licenseTextID(c.t.id) != licenseTextID(best.t.id) {
+			res.Confidence = ambiguousLicenseConfidence
+			break
+		}
+	}
+	return res, nil
+}
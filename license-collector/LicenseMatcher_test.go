@@ -0,0 +1,163 @@
+package licensecollector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testMITText = `MIT License
+
+Copyright (c) 2020 Acme Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`
+
+const testBSD3Text = `Copyright (c) 2003-2011 - Gustavo Niemeyer <gustavo@niemeyer.net>
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+    * Redistributions of source code must retain the above copyright notice,
+      this list of conditions and the following disclaimer.
+    * Redistributions in binary form must reproduce the above copyright notice,
+      this list of conditions and the following disclaimer in the documentation
+      and/or other materials provided with the distribution.
+    * Neither the name of the copyright holder nor the names of its
+      contributors may be used to endorse or promote products derived from
+      this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+`
+
+// testApacheHeader is the Apache-2.0 notice python-dateutil puts before its BSD-3-Clause license
+const testApacheHeader = `Copyright 2017- Paul Ganssle <paul@ganssle.io>
+Copyright 2017- dateutil contributors (see AUTHORS file)
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+The above license applies to all contributions after 2017-12-01, as well as
+all contributions that have been re-licensed (see AUTHORS file for the list of
+contributors who have re-licensed their code).
+--------------------------------------------------------------------------------
+dateutil - Extensions to the standard Python datetime library.
+
+`
+
+func TestNormalizeLicenseWords(t *testing.T) {
+	text := "Copyright (c) 2020 Acme\n\n1. Redistributions & re-use of the Licence;\n  (b) \"AS IS\""
+	want := []string{"redistributions", "and", "reuse", "of", "the", "license", "as", "is"}
+	if got := normalizeLicenseWords(text, true); !reflect.DeepEqual(got, want) {
+		t.Errorf("normalizeLicenseWords() = %v, want %v", got, want)
+	}
+}
+
+func TestParseLicenseTemplate(t *testing.T) {
+	template := "<<beginOptional>>Foo License<<endOptional>>\n\nCopyright (c) <<var;name=\"copyright\";original=\"<year> <owner>\";match=\".+\">>\n\n" +
+		"<<var;name=\"bullet\";original=\"1.\";match=\".{0,20}\">> Use of <<var;name=\"work\";original=\"this work\";match=\".+\">> is allowed as is."
+	tmpl := parseLicenseTemplate("Foo", template)
+	wantPattern := "^(?: foo license)?(?: [a-z0-9]+)*? use of(?: [a-z0-9]+)*? is allowed as is$"
+	if tmpl.pattern != wantPattern {
+		t.Errorf("pattern = %s, want %s", tmpl.pattern, wantPattern)
+	}
+	// the shingles across the replaceable part are not required
+	wantRequired := map[string]int{"is allowed as": 1, "allowed as is": 1}
+	if !reflect.DeepEqual(tmpl.required, wantRequired) {
+		t.Errorf("required = %v, want %v", tmpl.required, wantRequired)
+	}
+	for _, s := range []string{"foo license use", "use of this", "this work is", "allowed as is"} {
+		if _, ok := tmpl.allowed[s]; !ok {
+			t.Errorf("shingle %q is not allowed", s)
+		}
+	}
+}
+
+func TestMatchLicense(t *testing.T) {
+	mitParagraphs := strings.Split(testMITText, "\n\n")
+	mitParagraphs[2], mitParagraphs[3] = mitParagraphs[3], mitParagraphs[2]
+	tests := []struct {
+		name      string
+		text      string
+		wantID    string
+		wantExact bool
+		// wantConfidence is checked when set
+		wantConfidence float64
+		wantErr        bool
+	}{
+		{name: "MIT", text: testMITText, wantID: "MIT", wantExact: true, wantConfidence: 100},
+		{name: "MIT paragraphs out of order", text: strings.Join(mitParagraphs, "\n\n"), wantID: "MIT"},
+		{name: "BSD-3-Clause with a copyright holder", text: testBSD3Text, wantID: "BSD-3-Clause", wantExact: true, wantConfidence: 100},
+		{name: "LGPL-3.0 is not GPL-3.0", text: spdxLicenses["LGPL-3.0-only"].Text, wantID: "LGPL-3.0-only", wantExact: true, wantConfidence: 100},
+		{name: "GPL-3.0", text: spdxLicenses["GPL-3.0-only"].Text, wantID: "GPL-3.0-only", wantExact: true, wantConfidence: 100},
+		{name: "BSD-3-Clause-Clear is not BSD-3-Clause", text: spdxLicenses["BSD-3-Clause-Clear"].Text, wantID: "BSD-3-Clause-Clear", wantExact: true, wantConfidence: 100},
+		// the versions of a license text are one license, not an ambiguous match
+		{name: "GPL-1.0-or-later is GPL-1.0-only", text: spdxLicenses["GPL-1.0-or-later"].Text, wantID: "GPL-1.0-only", wantConfidence: 99.5},
+		// the OpenLDAP licenses 2.2 to 2.3 differ in a few words only
+		{name: "ambiguous", text: spdxLicenses["OLDAP-2.2.1"].Text, wantID: "OLDAP-2.2.1", wantConfidence: ambiguousLicenseConfidence},
+		{name: "BSD-3-Clause after an Apache-2.0 notice", text: testApacheHeader + testBSD3Text, wantID: "BSD-3-Clause"},
+		// python-dateutil's LICENSE: two licenses are too much of the text to be sure of either
+		{
+			name:    "python-dateutil",
+			text:    testApacheHeader + testBSD3Text + "\nThe above BSD License Applies to all code, even that also covered by Apache 2.0.\n",
+			wantID:  "BSD-3-Clause",
+			wantErr: true,
+		},
+		{name: "not a license", text: "This is the README of the project.\nRun make to build it.", wantErr: true},
+		{name: "empty", text: "Copyright (c) 2020 Acme", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := matchLicense(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("matchLicense() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.ID != tt.wantID || got.Exact != tt.wantExact {
+				t.Errorf("matchLicense() = %+v, want %s, exact %v", got, tt.wantID, tt.wantExact)
+			}
+			if tt.wantConfidence > 0 && got.Confidence != tt.wantConfidence {
+				t.Errorf("matchLicense() confidence = %v, want %v", got.Confidence, tt.wantConfidence)
+			}
+			if !tt.wantExact && got.Confidence == 100 {
+				t.Errorf("matchLicense() confidence of an inexact match = 100")
+			}
+		})
+	}
+}
@@ -0,0 +1,391 @@
+package licensecollector
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Evidence types of a license decision, see licenseEntry.Evidence
+const (
+	evidenceExactText = "exact text"
+	evidenceFuzzyText = "fuzzy text"
+	evidenceMetadata  = "package metadata"
+	evidenceHeader    = "source header"
+	evidenceManual    = "manual override"
+)
+
+// evidenceConfidence is the confidence of the evidence types without a score of their own
+var evidenceConfidence = map[string]float64{
+	evidenceMetadata: 60,
+	evidenceManual:   100,
+}
+
+// licenseEntry is the license collected for a single package
+type licenseEntry struct {
+	Name string `json:"name"`
+	// Version is the package version, every installed version has its own entry
+	Version string `json:"version,omitempty"`
+	// Path is the install path of the package
+	Path string `json:"path,omitempty"`
+	// Replace is the replacement of a go module, "path@version" or a local directory
+	Replace string `json:"replace,omitempty"`
+	// Group is the dependency group the package came from, e.g. devDependencies
+	Group string `json:"group,omitempty"`
+	// Workspace is the npm workspace using the package, empty for the root project
+	Workspace string `json:"workspace,omitempty"`
+	// Binary is the go binary importing the package, for per binary notices
+	Binary string `json:"binary,omitempty"`
+	// Type is the license type, an SPDX license identifier with its exception, if any
+	Type string `json:"license,omitempty"`
+	// Expression is the SPDX license expression of the package, Type is one of its licenses
+	Expression string `json:"expression,omitempty"`
+	// Selected is the branch of Expression the package is complied under, when there is a choice
+	Selected string `json:"selected,omitempty"`
+	// File is the license file the type was detected from
+	File string `json:"file,omitempty"`
+	// Confidence is how sure the license decision is, in percent: the license match score of File,
+	// or the confidence of the evidence type
+	Confidence float64 `json:"confidence,omitempty"`
+	// Evidence is the evidence type behind the license decision, e.g. exact text or package metadata
+	Evidence string `json:"evidence,omitempty"`
+	// Text is placed as is instead of the license type text (manualLicense.json, declared licenses)
+	Text string `json:"text"`
+	// FileText is the content of File, reported instead of the license type text with original texts
+	FileText string `json:"-"`
+	// Hash identifies the reported text with original texts, packages sharing a text share its hash
+	Hash string `json:"hash,omitempty"`
+	// Copyrights are the copyright statements of the package
+	Copyrights []copyright `json:"copyrights,omitempty"`
+	// Notice is the content of the NOTICE file of the package
+	Notice string `json:"notice,omitempty"`
+	// NoticeMissing tells why the package should have a NOTICE file it does not have
+	NoticeMissing string `json:"noticeMissing,omitempty"`
+	// Conflict describes a disagreement between the declared license and the license file
+	Conflict string `json:"conflict,omitempty"`
+	// Embedded is the directory of a third party component inside the package, relative to the
+	// package, with a license of its own
+	Embedded string `json:"embedded,omitempty"`
+}
+
+// label names the entry in the text report, "name@version (install path)" or
+// "path@version => replacement"
+func (e *licenseEntry) label() string {
+	res := e.Name
+	if len(e.Version) > 0 {
+		res = npmPackageID(e.Name, e.Version)
+	}
+	if len(e.Path) > 0 && e.Path != e.Name && e.Path != res {
+		res += " (" + e.Path + ")"
+	}
+	if len(e.Replace) > 0 {
+		res += " => " + e.Replace
+	}
+	if len(e.Embedded) > 0 {
+		res += " (embedded " + e.Embedded + ")"
+	}
+	return res
+}
+
+// heading introduces the entry in the text report: its label, then its copyright statements
+func (e *licenseEntry) heading() string {
+	res := e.label() + "\n"
+	if len(e.Selected) > 0 {
+		res += "  License: " + e.Expression + ", complied under " + e.Selected + "\n"
+	} else if e.Expression != e.Type {
+		res += "  License: " + e.Expression + "\n"
+	}
+	for _, c := range e.Copyrights {
+		res += "  " + c.String() + "\n"
+	}
+	return res
+}
+
+// licenseCollection holds the collected packages, unique per binary, workspace, group, name
+// and version. A package with several license files has an entry per license type, and an
+// entry per embedded component license.
+type licenseCollection struct {
+	entries []*licenseEntry
+	// preferred are the license identifiers in order of preference, to choose between licenses
+	preferred []string
+	// deepScan reports the licenses of the components embedded in the packages
+	deepScan bool
+	// binaries are the go binaries of the per binary notices
+	binaries []string
+}
+
+// add adds an entry, the first entry of a package wins
+func (c *licenseCollection) add(e licenseEntry) *licenseEntry {
+	for _, existing := range c.entries {
+		if existing.Binary == e.Binary && existing.Workspace == e.Workspace && existing.Group == e.Group && strings.EqualFold(existing.Name, e.Name) && existing.Version == e.Version && existing.Type == e.Type && existing.Embedded == e.Embedded {
+			return existing
+		}
+	}
+	c.entries = append(c.entries, &e)
+	return &e
+}
+
+// addExpression adds an entry per license of the expression branch complied under, each
+// with the license file it was detected from. The licenses without a file come from evidence.
+func (c *licenseCollection) addExpression(dep dependency, lDir string, expression *licenseExpression, licenses []licenseFile, evidence string) []*licenseEntry {
+	chosen := expression.choose(c.preferred)
+	var res []*licenseEntry
+	for _, l := range chosen.licenses() {
+		entry := dep.entry(lDir, l.String(), "", evidence)
+		entry.Expression = expression.String()
+		if chosen.String() != entry.Expression {
+			entry.Selected = chosen.String()
+		}
+		for _, file := range licenses {
+			if licenseMatchesFile(l, file) {
+				entry.File = file.File
+				entry.Confidence = file.Confidence
+				entry.Evidence = file.Evidence
+				entry.FileText = file.Text
+				break
+			}
+		}
+		res = append(res, c.add(entry))
+	}
+	return res
+}
+
+// reviewReport lists the license decisions under the confidence threshold, once per package and license
+func (c *licenseCollection) reviewReport(threshold float64) string {
+	res := ""
+	seen := map[string]struct{}{}
+	for _, e := range c.entries {
+		if e.Confidence >= threshold {
+			continue
+		}
+		license := e.Type
+		if len(license) == 0 {
+			license = "custom license text"
+		}
+		line := fmt.Sprintf("%s: %s (%s, %v%%)\n", e.label(), license, e.Evidence, e.Confidence)
+		if _, ok := seen[line]; !ok {
+			seen[line] = struct{}{}
+			res += line
+		}
+	}
+	return res
+}
+
+// split splits the entries by notice file, the main file first
+func (c *licenseCollection) split(notice func(e *licenseEntry) string) (notices []string, byNotice map[string]*licenseCollection) {
+	byNotice = map[string]*licenseCollection{}
+	for _, e := range c.entries {
+		key := notice(e)
+		if _, ok := byNotice[key]; !ok {
+			notices = append(notices, key)
+			byNotice[key] = &licenseCollection{}
+		}
+		byNotice[key].entries = append(byNotice[key].entries, e)
+	}
+	sort.Strings(notices)
+	return notices, byNotice
+}
+
+// versionChanges lists the packages whose licenses differ between their installed versions
+func (c *licenseCollection) versionChanges() string {
+	var names []string
+	byName := map[string][]*licenseEntry{}
+	for _, e := range c.entries {
+		// the licenses of embedded components are not the license of the package
+		if len(e.Version) == 0 || len(e.Embedded) > 0 {
+			continue
+		}
+		key := strings.ToLower(e.Name)
+		if _, ok := byName[key]; !ok {
+			names = append(names, key)
+		}
+		byName[key] = append(byName[key], e)
+	}
+	sort.Strings(names)
+	res := ""
+	for _, name := range names {
+		var versions []string
+		licenses := map[string][]string{}
+		for _, e := range byName[name] {
+			if _, ok := licenses[e.Version]; !ok {
+				versions = append(versions, e.Version)
+			}
+			l := e.Type
+			if len(l) == 0 {
+				l = "custom license text"
+			}
+			if !InStringSlice(licenses[e.Version], l) {
+				licenses[e.Version] = append(licenses[e.Version], l)
+			}
+		}
+		changed := false
+		var descriptions []string
+		for _, v := range versions {
+			sort.Strings(licenses[v])
+			descriptions = append(descriptions, v+": "+strings.Join(licenses[v], " and "))
+			changed = changed || strings.Join(licenses[v], ",") != strings.Join(licenses[versions[0]], ",")
+		}
+		if changed {
+			res += byName[name][0].Name + ": " + strings.Join(descriptions, ", ") + "\n"
+		}
+	}
+	return res
+}
+
+// groupOrder sorts Go packages first, then the npm groups in precedence order,
+// then the go modules used by tests only
+func groupOrder(group string) int {
+	if group == goTestOnlyGroup {
+		return len(npmGroups) + 1
+	}
+	for i, g := range npmGroups {
+		if g == group {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// groups returns the entries split by group, in group order
+func (c *licenseCollection) groups() (groups []string, byGroup map[string][]*licenseEntry) {
+	byGroup = map[string][]*licenseEntry{}
+	for _, e := range c.entries {
+		if _, ok := byGroup[e.Group]; !ok {
+			groups = append(groups, e.Group)
+		}
+		byGroup[e.Group] = append(byGroup[e.Group], e)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groupOrder(groups[i]) < groupOrder(groups[j]) })
+	return groups, byGroup
+}
+
+// licenseTextHash identifies a license text, line endings and surrounding blank lines aside
+func licenseTextHash(text string) string {
+	text = strings.TrimSpace(strings.Replace(text, "\r\n", "\n", -1))
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// sharedTexts lists the texts of entries once, each with the packages sharing it
+func sharedTexts(entries []*licenseEntry) string {
+	var hashes []string
+	byHash := map[string][]*licenseEntry{}
+	for _, e := range entries {
+		if _, ok := byHash[e.Hash]; !ok {
+			hashes = append(hashes, e.Hash)
+		}
+		byHash[e.Hash] = append(byHash[e.Hash], e)
+	}
+	res := ""
+	for _, hash := range hashes {
+		for _, e := range byHash[hash] {
+			res += e.heading()
+		}
+		res += byHash[hash][0].Text + "\n"
+	}
+	return res
+}
+
+// generateLicenseFile creates the report. The license type text of an entry is the SPDX
+// license text, or the content of its license file with original texts.
+func generateLicenseFile(collection *licenseCollection, opts Options) ([]byte, error) {
+	format, originalTexts := opts.FileFormat, opts.OriginalTexts
+	wrongLicense := map[string][]string{}
+	for _, e := range collection.entries {
+		if originalTexts && len(e.FileText) > 0 {
+			e.Text = e.FileText
+		} else if len(e.Type) > 0 {
+			fullLicense, ok := licenseText(e.Type)
+			if !ok {
+				wrongLicense[e.Type] = append(wrongLicense[e.Type], e.label())
+				continue
+			}
+			e.Text = fullLicense
+		}
+		if originalTexts {
+			e.Hash = licenseTextHash(e.Text)
+		}
+	}
+	if len(wrongLicense) > 0 {
+		errMsg := "Wrong license files for the following libs"
+		for k, v := range wrongLicense {
+			errMsg += "\n " + k + ": " + fmt.Sprintf("%v", v)
+		}
+		var errRes []byte
+		if format == "json" {
+			errRes = []byte("[]")
+		} else {
+			errRes = []byte("")
+		}
+		return errRes, fmt.Errorf(errMsg)
+	}
+	if format == "json" {
+		entries := collection.entries
+		if entries == nil {
+			entries = []*licenseEntry{}
+		}
+		bRes, err := json.Marshal(entries)
+		if err != nil {
+			return []byte("[]"), err
+		}
+		return bRes, nil
+	}
+
+	res := ""
+	conflicts := ""
+	groups, byGroup := collection.groups()
+	for _, group := range groups {
+		// the production packages keep the plain layout, other groups are reported separately
+		if len(group) > 0 && group != npmDependencies {
+			res += "\n=== " + group + " ===\n\n"
+		}
+		for _, e := range byGroup[group] {
+			if len(e.Conflict) > 0 {
+				conflicts += e.label() + ": " + e.Conflict + "\n"
+			}
+		}
+		if originalTexts {
+			res += sharedTexts(byGroup[group])
+			continue
+		}
+		var types []string
+		typeProjects := map[string]string{}
+		projects := ""
+		for _, e := range byGroup[group] {
+			if len(e.Type) == 0 {
+				projects += e.heading() + e.Text + "\n"
+				continue
+			}
+			if _, ok := typeProjects[e.Type]; !ok {
+				types = append(types, e.Type)
+			}
+			typeProjects[e.Type] += e.heading()
+		}
+		sort.Strings(types)
+		for _, lType := range types {
+			text, _ := licenseText(lType)
+			res += typeProjects[lType] + text + "\n"
+		}
+		res += projects
+	}
+	notices, missingNotices := noticesReport(collection.entries)
+	if len(notices) > 0 {
+		res += "\nNOTICE files\n\n" + notices
+	}
+	if len(missingNotices) > 0 {
+		res += "\nMissing NOTICE files\n" + missingNotices
+	}
+	if review := collection.reviewReport(opts.ReviewThreshold); len(review) > 0 {
+		res += "\nNeeds review\n" + review
+	}
+	if changes := collection.versionChanges(); len(changes) > 0 {
+		res += "\nLicense changes between versions\n" + changes
+	}
+	if len(conflicts) > 0 {
+		res += "\nLicense conflicts\n" + conflicts
+	}
+	return []byte(res), nil
+}
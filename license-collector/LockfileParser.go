@@ -0,0 +1,166 @@
+package licensecollector
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+)
+
+// lockNode is an entry of an indentation based lockfile: yarn v1 lockfiles and the
+// YAML subset used by Yarn Berry and pnpm lockfiles. Flow collections ({...} and [...])
+// and block scalars are kept as raw values.
+type lockNode struct {
+	Key      string
+	Value    string
+	Children []*lockNode
+}
+
+// child returns the child with the given key, or nil
+func (n *lockNode) child(key string) *lockNode {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.Children {
+		if c.Key == key {
+			return c
+		}
+	}
+	return nil
+}
+
+// children returns the children of a node, nil safe
+func (n *lockNode) children() []*lockNode {
+	if n == nil {
+		return nil
+	}
+	return n.Children
+}
+
+// childValue returns the value of the child with the given key, or an empty string
+func (n *lockNode) childValue(key string) string {
+	if c := n.child(key); c != nil {
+		return c.Value
+	}
+	return ""
+}
+
+// parseLockfile parses lockfile data into a tree rooted at an empty node
+func parseLockfile(data []byte) (*lockNode, error) {
+	root := &lockNode{}
+	type level struct {
+		indent int
+		node   *lockNode
+	}
+	stack := []level{{indent: -1, node: root}}
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		raw := strings.TrimRight(scanner.Text(), " \t\r")
+		content := strings.TrimLeft(raw, " ")
+		if len(content) == 0 || content[0] == '#' || content == "---" {
+			continue
+		}
+		indent := len(raw) - len(content)
+		item := strings.HasPrefix(content, "- ") || content == "-"
+		for len(stack) > 1 && stack[len(stack)-1].indent >= indent {
+			// YAML allows the items of a sequence at the indentation of their key
+			if top := stack[len(stack)-1]; item && top.indent == indent && top.node.Key != "-" && len(top.node.Value) == 0 {
+				break
+			}
+			stack = stack[:len(stack)-1]
+		}
+		node := &lockNode{}
+		if item {
+			node.Key = "-"
+			node.Value = unquoteLockValue(strings.TrimSpace(strings.TrimPrefix(content, "-")))
+		} else {
+			node.Key, node.Value = splitLockLine(content)
+		}
+		parent := stack[len(stack)-1].node
+		parent.Children = append(parent.Children, node)
+		stack = append(stack, level{indent: indent, node: node})
+	}
+	return root, scanner.Err()
+}
+
+// splitLockLine splits "key: value" (YAML) or "key value" (yarn v1) lines,
+// ignoring separators inside quotes
+func splitLockLine(line string) (key string, value string) {
+	var quote byte
+	firstSpace := -1
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ':' && (i == len(line)-1 || line[i+1] == ' '):
+			return unquoteLockKey(line[:i]), unquoteLockValue(strings.TrimSpace(line[i+1:]))
+		case c == ' ' && firstSpace < 0:
+			firstSpace = i
+		}
+	}
+	if firstSpace > 0 {
+		return unquoteLockKey(line[:firstSpace]), unquoteLockValue(strings.TrimSpace(line[firstSpace+1:]))
+	}
+	return unquoteLockKey(line), ""
+}
+
+// unquoteLockKey unquotes a key. Keys listing several quoted descriptors
+// ("a@^1", "a@^1.1") are kept as they are, use splitLockKey for them.
+func unquoteLockKey(key string) string {
+	key = strings.TrimSpace(key)
+	parts := splitLockKey(key)
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return key
+}
+
+// splitLockKey splits a key holding several comma separated descriptors
+func splitLockKey(key string) []string {
+	var res []string
+	var quote byte
+	start := 0
+	for i := 0; i <= len(key); i++ {
+		if i < len(key) {
+			c := key[i]
+			if quote != 0 {
+				if c == quote {
+					quote = 0
+				}
+				continue
+			}
+			if c == '"' || c == '\'' {
+				quote = c
+				continue
+			}
+			if c != ',' {
+				continue
+			}
+		}
+		res = append(res, unquoteLockValue(strings.TrimSpace(key[start:i])))
+		start = i + 1
+	}
+	return res
+}
+
+func unquoteLockValue(value string) string {
+	if len(value) < 2 {
+		return value
+	}
+	switch {
+	case value[0] == '"' && value[len(value)-1] == '"':
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+	case value[0] == '\'' && value[len(value)-1] == '\'':
+		return strings.Replace(value[1:len(value)-1], "''", "'", -1)
+	}
+	return value
+}
@@ -0,0 +1,88 @@
+package licensecollector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// formatLockNode prints a lockfile tree as "key=value" lines indented by depth, for comparisons
+func formatLockNode(n *lockNode, depth int, sb *strings.Builder) {
+	for _, c := range n.Children {
+		sb.WriteString(strings.Repeat("  ", depth) + c.Key + "=" + c.Value + "\n")
+		formatLockNode(c, depth+1, sb)
+	}
+}
+
+func TestParseLockfile(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "yarn v1",
+			data: "# yarn lockfile v1\n\n\n\"@s/a@^1.0.0\", \"@s/a@^1.1.0\":\n  version \"1.1.0\"\n  dependencies:\n    b \"^2.0.0\"\n\nb@^2.0.0:\n  version \"2.0.1\"\n",
+			want: "\"@s/a@^1.0.0\", \"@s/a@^1.1.0\"=\n  version=1.1.0\n  dependencies=\n    b=^2.0.0\nb@^2.0.0=\n  version=2.0.1\n",
+		},
+		{
+			name: "yarn berry",
+			data: "__metadata:\n  version: 6\n\n\"a@npm:^1.0.0\":\n  version: 1.0.0\n  dependencies:\n    b: \"npm:^2.0.0\"\n  dependenciesMeta:\n    b:\n      optional: true\n  linkType: hard\n",
+			want: "__metadata=\n  version=6\na@npm:^1.0.0=\n  version=1.0.0\n  dependencies=\n    b=npm:^2.0.0\n  dependenciesMeta=\n    b=\n      optional=true\n  linkType=hard\n",
+		},
+		{
+			name: "pnpm",
+			data: "lockfileVersion: '6.0'\n\nimporters:\n\n  .:\n    dependencies:\n      '@s/a':\n        specifier: ^1.0.0\n        version: 1.0.0(b@2.0.0)\n\npackages:\n\n  /@s/a@1.0.0(b@2.0.0):\n    resolution: {integrity: sha512-abc}\n    os: [darwin]\n",
+			want: "lockfileVersion=6.0\nimporters=\n  .=\n    dependencies=\n      @s/a=\n        specifier=^1.0.0\n        version=1.0.0(b@2.0.0)\npackages=\n  /@s/a@1.0.0(b@2.0.0)=\n    resolution={integrity: sha512-abc}\n    os=[darwin]\n",
+		},
+		{
+			name: "indented sequence",
+			data: "packages:\n  - 'packages/*'\n  - \"!packages/private\"\n",
+			want: "packages=\n  -=packages/*\n  -=!packages/private\n",
+		},
+		{
+			name: "sequence at the key indentation",
+			data: "packages:\n- 'packages/*'\n- \"!packages/private\"\ncatalog:\n  react: ^18.0.0\n",
+			want: "packages=\n  -=packages/*\n  -=!packages/private\ncatalog=\n  react=^18.0.0\n",
+		},
+		{
+			name: "nested sequence at the key indentation",
+			data: "a:\n  os:\n  - darwin\n  cpu:\n  - arm64\nb: 1\n",
+			want: "a=\n  os=\n    -=darwin\n  cpu=\n    -=arm64\nb=1\n",
+		},
+		{
+			name: "quotes",
+			data: "\"a: b\": 'it''s'\nc: \"x\\\"y\"\n",
+			want: "a: b=it's\nc=x\"y\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parseLockfile([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var sb strings.Builder
+			formatLockNode(root, 0, &sb)
+			if got := sb.String(); got != tt.want {
+				t.Errorf("parseLockfile() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitLockKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"a@^1.0.0", []string{"a@^1.0.0"}},
+		{"a@^1.0.0, a@^1.1.0", []string{"a@^1.0.0", "a@^1.1.0"}},
+		{"\"@s/a@^1.0.0\", \"@s/a@>=1, <2\"", []string{"@s/a@^1.0.0", "@s/a@>=1, <2"}},
+	}
+	for _, tt := range tests {
+		if got := splitLockKey(tt.key); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitLockKey(%s) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package licensecollector
+
+import (
+	"log"
+	"path"
+	"strings"
+)
+
+// noticeFileNames are the names of the NOTICE files Apache-2.0 section 4(d) asks to pass along, case insensitive
+var noticeFileNames = []string{"notice", "notice.txt"}
+
+// readNotice returns the NOTICE file of a package directory and its content
+func readNotice(fsys sourceFS, dir string) (file string, text string) {
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return "", ""
+	}
+	for _, e := range entries {
+		name := e.Name
+		if e.IsDir || !InStringSlice(noticeFileNames, name) {
+			continue
+		}
+		data, err := fsys.ReadFile(path.Join(dir, name))
+		if err != nil {
+			log.Printf("Failed reading %s: %s\n", path.Join(dir, name), err)
+			continue
+		}
+		return path.Join(dir, name), string(data)
+	}
+	return "", ""
+}
+
+// shipsNotice checks if the package.json "files" list a root NOTICE file, e.g. "NOTICE"
+// or "NOTICE*": the package is published with it
+func (p *npmPackageJSON) shipsNotice() bool {
+	for _, pattern := range p.Files {
+		name := strings.ToLower(strings.TrimPrefix(path.Clean(pattern), "./"))
+		if InStringSlice(noticeFileNames, name) || (strings.HasPrefix(name, "notice") && strings.ContainsAny(name, "*?[")) {
+			return true
+		}
+	}
+	return false
+}
+
+// noticesReport lists the NOTICE files of the entries once per package, and the missing ones
+func noticesReport(entries []*licenseEntry) (notices string, missing string) {
+	seen := map[string]struct{}{}
+	for _, e := range entries {
+		label := e.label()
+		if _, ok := seen[label]; ok {
+			continue
+		}
+		seen[label] = struct{}{}
+		if len(e.Notice) > 0 {
+			notices += label + "\n" + strings.TrimRight(e.Notice, "\r\n") + "\n\n"
+		}
+		if len(e.NoticeMissing) > 0 {
+			missing += label + ": " + e.NoticeMissing + "\n"
+		}
+	}
+	return notices, missing
+}
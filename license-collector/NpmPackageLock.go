@@ -0,0 +1,192 @@
+package licensecollector
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"path"
+	"sort"
+	"strings"
+)
+
+const npmPackageLockFile = "package-lock.json"
+
+// npmLockPackage is an installed package in lockfile version 1, which lists
+// nested packages in Dependencies
+type npmLockPackage struct {
+	Version      string                    `json:"version"`
+	Dev          bool                      `json:"dev"`
+	Optional     bool                      `json:"optional"`
+	Dependencies map[string]npmLockPackage `json:"dependencies"`
+}
+
+// group returns the dependency group of a package from its lockfile flags
+func (p npmLockPackage) group() string {
+	switch {
+	case p.Dev:
+		return npmDevDependencies
+	case p.Optional:
+		return npmOptionalDependencies
+	}
+	return npmDependencies
+}
+
+// npmLockEntry is an entry of the packages object of lockfile versions 2 and 3,
+// keyed by install path. Workspaces are entries outside node_modules, and are
+// linked into node_modules.
+type npmLockEntry struct {
+	Version              string            `json:"version"`
+	Resolved             string            `json:"resolved"`
+	Link                 bool              `json:"link"`
+	Optional             bool              `json:"optional"`
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+}
+
+type npmPackageLock struct {
+	LockfileVersion int                       `json:"lockfileVersion"`
+	Packages        map[string]npmLockEntry   `json:"packages"`
+	Dependencies    map[string]npmLockPackage `json:"dependencies"`
+}
+
+// readPackageLock returns the packages of every workspace in a package-lock.json,
+// with their install path and dependency group.
+// Optional packages are skipped when they were not installed on this platform.
+func readPackageLock(fileName string, fsys sourceFS, workspaces npmWorkspaces) ([]npmPackage, error) {
+	log.Println("Processing package lock file: ", fileName)
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	lock := npmPackageLock{}
+	if err = json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+	var packages []npmPackage
+	if lock.LockfileVersion >= 2 && lock.Packages != nil {
+		for _, w := range workspaces {
+			packages = append(packages, lock.resolveWorkspace(w, workspaces, fsys)...)
+		}
+		return packages, nil
+	}
+
+	// lockfile version 1 predates workspaces
+	installed := map[string]npmLockPackage{}
+	flattenLockV1("", lock.Dependencies, installed)
+	for installPath, p := range installed {
+		if _, err := fsys.ReadDir(installPath); p.Optional && err != nil {
+			continue
+		}
+		name, err := npmPackageNameFromPath(installPath)
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, npmPackage{Name: name, Version: p.Version, Path: installPath, Group: p.group()})
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Path < packages[j].Path })
+	return packages, nil
+}
+
+// npmLockDependency is a dependency name of the package installed at from, looked up
+// in the node_modules directories above it
+type npmLockDependency struct {
+	from, name string
+	optional   bool
+}
+
+// lookup resolves a dependency like node does: the closest node_modules/<name>
+// up from the dependent package
+func (l *npmPackageLock) lookup(from, name string) (string, bool) {
+	dirs := strings.Split(from, "/")
+	for i := len(dirs); i >= 0; i-- {
+		key := path.Join(path.Join(dirs[:i]...), nodeModules, name)
+		if _, ok := l.Packages[key]; ok {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// resolveWorkspace walks the lockfile entries from the entry of a workspace directory,
+// looking names up the way node does. Links to other workspaces are followed to their
+// directory entry, which is not itself a package to report.
+func (l *npmPackageLock) resolveWorkspace(w npmWorkspace, workspaces npmWorkspaces, fsys sourceFS) []npmPackage {
+	root := l.Packages[w.Dir]
+	seen := map[string]struct{}{w.Dir: {}}
+	var res []npmPackage
+	for _, group := range npmGroups {
+		var queue []npmLockDependency
+		for name := range root.groupDependencies(group) {
+			queue = append(queue, npmLockDependency{from: w.Dir, name: name, optional: group == npmOptionalDependencies})
+		}
+		for len(queue) > 0 {
+			dep := queue[0]
+			queue = queue[1:]
+			key, ok := l.lookup(dep.from, dep.name)
+			if !ok {
+				// peer and optional dependencies may not be installed
+				continue
+			}
+			entry := l.Packages[key]
+			if entry.Link {
+				// another workspace, first party, but its dependencies are not
+				key = entry.Resolved
+				entry = l.Packages[key]
+			}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			optional := dep.optional || entry.Optional
+			if workspaces.byDir(key) == nil {
+				installPath := strings.TrimPrefix(key, nodeModules+"/")
+				if _, err := fsys.ReadDir(installPath); optional && err != nil {
+					continue
+				}
+				name, err := npmPackageNameFromPath(installPath)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+				res = append(res, npmPackage{Name: name, Version: entry.Version, Path: installPath, Group: group, Workspace: w.label()})
+			}
+			for _, depGroup := range []string{npmDependencies, npmOptionalDependencies, npmPeerDependencies} {
+				for name := range entry.groupDependencies(depGroup) {
+					queue = append(queue, npmLockDependency{from: key, name: name, optional: optional || depGroup != npmDependencies})
+				}
+			}
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Path < res[j].Path })
+	return res
+}
+
+// groupDependencies returns the ranges an entry requires in a group. Only the entries
+// of workspaces record devDependencies.
+func (e npmLockEntry) groupDependencies(group string) map[string]string {
+	switch group {
+	case npmDependencies:
+		return e.Dependencies
+	case npmOptionalDependencies:
+		return e.OptionalDependencies
+	case npmPeerDependencies:
+		return e.PeerDependencies
+	case npmDevDependencies:
+		return e.DevDependencies
+	}
+	return nil
+}
+
+// flattenLockV1 turns the nested lockfile version 1 dependencies into install paths
+func flattenLockV1(parent string, dependencies map[string]npmLockPackage, installed map[string]npmLockPackage) {
+	for name, p := range dependencies {
+		installPath := name
+		if len(parent) > 0 {
+			installPath = path.Join(parent, nodeModules, name)
+		}
+		installed[installPath] = p
+		flattenLockV1(installPath, p.Dependencies, installed)
+	}
+}
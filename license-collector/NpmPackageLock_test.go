@@ -0,0 +1,125 @@
+package licensecollector
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// npmPackageSummaries formats packages as "path@version group", for comparisons
+func npmPackageSummaries(packages []npmPackage) []string {
+	var res []string
+	for _, p := range packages {
+		summary := p.Path + "@" + p.Version + " " + p.Group
+		if len(p.Workspace) > 0 {
+			summary += " " + p.Workspace
+		}
+		res = append(res, summary)
+	}
+	return res
+}
+
+func TestReadPackageLock(t *testing.T) {
+	tests := []struct {
+		name      string
+		lock      string
+		installed []string
+		want      []string
+	}{
+		{
+			name: "lockfile v3",
+			lock: `{"lockfileVersion": 3, "packages": {
+				"": {"dependencies": {"a": "^1.0.0", "@s/c": "^1.0.0"}, "optionalDependencies": {"o": "^1.0.0"}, "devDependencies": {"d": "^1.0.0"}},
+				"node_modules/a": {"version": "1.0.0", "dependencies": {"b": "^2.0.0"}},
+				"node_modules/a/node_modules/b": {"version": "2.0.0"},
+				"node_modules/b": {"version": "1.0.0"},
+				"node_modules/@s/c": {"version": "1.1.0", "dependencies": {"b": "^1.0.0"}},
+				"node_modules/d": {"version": "1.0.0", "dev": true, "dependencies": {"a": "^1.0.0"}},
+				"node_modules/o": {"version": "1.0.0", "optional": true}
+			}}`,
+			want: []string{
+				"@s/c@1.1.0 dependencies",
+				"a@1.0.0 dependencies",
+				"a/node_modules/b@2.0.0 dependencies",
+				"b@1.0.0 dependencies",
+				"d@1.0.0 devDependencies",
+			},
+		},
+		{
+			name: "installed optional dependency",
+			lock: `{"lockfileVersion": 2, "packages": {
+				"": {"optionalDependencies": {"o": "^1.0.0"}},
+				"node_modules/o": {"version": "1.0.0", "optional": true}
+			}}`,
+			installed: []string{"o"},
+			want:      []string{"o@1.0.0 optionalDependencies"},
+		},
+		{
+			name: "lockfile v1",
+			lock: `{"lockfileVersion": 1, "dependencies": {
+				"a": {"version": "1.0.0", "dependencies": {"b": {"version": "2.0.0"}}},
+				"d": {"version": "1.0.0", "dev": true},
+				"o": {"version": "1.0.0", "optional": true}
+			}}`,
+			want: []string{
+				"a@1.0.0 dependencies",
+				"a/node_modules/b@2.0.0 dependencies",
+				"d@1.0.0 devDependencies",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			fileName := filepath.Join(dir, npmPackageLockFile)
+			if err := ioutil.WriteFile(fileName, []byte(tt.lock), 0644); err != nil {
+				t.Fatal(err)
+			}
+			nodeModulesDir := filepath.Join(dir, nodeModules)
+			for _, installed := range append([]string{""}, tt.installed...) {
+				if err := os.MkdirAll(filepath.Join(nodeModulesDir, installed), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			packages, err := readPackageLock(fileName, dirFS(nodeModulesDir), npmWorkspaces{{Package: &npmPackageJSON{}}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := npmPackageSummaries(packages); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readPackageLock() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadPackageLockWorkspaces(t *testing.T) {
+	lock := `{"lockfileVersion": 3, "packages": {
+		"": {"workspaces": ["packages/*"]},
+		"packages/web": {"name": "web", "dependencies": {"lib": "*", "a": "^1.0.0"}},
+		"packages/lib": {"name": "lib", "dependencies": {"b": "^1.0.0"}},
+		"node_modules/web": {"resolved": "packages/web", "link": true},
+		"node_modules/lib": {"resolved": "packages/lib", "link": true},
+		"node_modules/a": {"version": "1.0.0"},
+		"node_modules/b": {"version": "1.0.0"}
+	}}`
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, npmPackageLockFile)
+	if err := ioutil.WriteFile(fileName, []byte(lock), 0644); err != nil {
+		t.Fatal(err)
+	}
+	workspaces := npmWorkspaces{
+		{Package: &npmPackageJSON{}},
+		{Dir: "packages/lib", Package: &npmPackageJSON{Name: "lib"}},
+		{Dir: "packages/web", Package: &npmPackageJSON{Name: "web"}},
+	}
+	packages, err := readPackageLock(fileName, dirFS(dir), workspaces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"b@1.0.0 dependencies lib", "a@1.0.0 dependencies web", "b@1.0.0 dependencies web"}
+	if got := npmPackageSummaries(packages); !reflect.DeepEqual(got, want) {
+		t.Errorf("readPackageLock() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,342 @@
+package licensecollector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path"
+	"sort"
+	"strings"
+)
+
+const nodeModules = "node_modules"
+const npmPackageFile = "package.json"
+
+// package.json dependency groups
+const (
+	npmDependencies         = "dependencies"
+	npmOptionalDependencies = "optionalDependencies"
+	npmPeerDependencies     = "peerDependencies"
+	npmDevDependencies      = "devDependencies"
+)
+
+// npmGroups are the dependency groups in precedence order: a package reachable
+// from several groups belongs to the first of them
+var npmGroups = []string{npmDependencies, npmOptionalDependencies, npmPeerDependencies, npmDevDependencies}
+
+// npmPackageJSON holds the parts of a package.json the collector uses
+type npmPackageJSON struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	License              json.RawMessage   `json:"license"`
+	Licenses             json.RawMessage   `json:"licenses"`
+	Workspaces           json.RawMessage   `json:"workspaces"`
+	Author               json.RawMessage   `json:"author"`
+	Files                []string          `json:"files"`
+}
+
+// npmPerson is the {"name": "...", "email": "...", "url": "..."} person format
+type npmPerson struct {
+	Name string `json:"name"`
+}
+
+// author returns the name of the author, "Name <email> (url)" or {"name": "Name"}
+func (p *npmPackageJSON) author() string {
+	var res string
+	var person npmPerson
+	if json.Unmarshal(p.Author, &res) != nil && json.Unmarshal(p.Author, &person) == nil {
+		res = person.Name
+	}
+	return res
+}
+
+// groupDependencies returns the dependencies of a group, as name to version range
+func (p *npmPackageJSON) groupDependencies(group string) map[string]string {
+	switch group {
+	case npmDependencies:
+		return p.Dependencies
+	case npmOptionalDependencies:
+		return p.OptionalDependencies
+	case npmPeerDependencies:
+		return p.PeerDependencies
+	case npmDevDependencies:
+		return p.DevDependencies
+	}
+	return nil
+}
+
+// npmLicenseObject is the legacy {"type": "MIT", "url": "..."} license format
+type npmLicenseObject struct {
+	Type string `json:"type"`
+}
+
+// declaredLicense returns the license expression declared in "license", or in the
+// legacy "licenses" array. "SEE LICENSE IN <file>" does not name a license.
+func (p *npmPackageJSON) declaredLicense() string {
+	var res string
+	var obj npmLicenseObject
+	if json.Unmarshal(p.License, &res) != nil && json.Unmarshal(p.License, &obj) == nil {
+		res = obj.Type
+	}
+	if len(res) == 0 {
+		var list []json.RawMessage
+		_ = json.Unmarshal(p.Licenses, &list)
+		var types []string
+		for _, raw := range list {
+			var t string
+			if json.Unmarshal(raw, &t) != nil && json.Unmarshal(raw, &obj) == nil {
+				t = obj.Type
+			}
+			if len(t) > 0 {
+				types = append(types, t)
+			}
+		}
+		res = strings.Join(types, " OR ")
+		if len(types) > 1 {
+			res = "(" + res + ")"
+		}
+	}
+	res = strings.TrimSpace(res)
+	if strings.HasPrefix(strings.ToUpper(res), "SEE LICENSE IN") {
+		return ""
+	}
+	return res
+}
+
+// declaredLicenseIDs returns the license identifiers of an SPDX expression, without exceptions
+func declaredLicenseIDs(expression string) []string {
+	var ids []string
+	tokens := strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(expression))
+	for i := 0; i < len(tokens); i++ {
+		switch strings.ToUpper(tokens[i]) {
+		case "AND", "OR":
+		case "WITH":
+			i++
+		default:
+			ids = append(ids, tokens[i])
+		}
+	}
+	return ids
+}
+
+// declaredLicenseMatches checks that a declared license expression allows the detected license type
+func declaredLicenseMatches(expression string, lType string) bool {
+	if alias, ok := licenseAlias(expression); ok {
+		expression = alias
+	}
+	for _, id := range declaredLicenseIDs(expression) {
+		if strings.EqualFold(licenseTextID(id), licenseTextID(lType)) {
+			return true
+		}
+	}
+	return false
+}
+
+func readPackageJSON(fileName string) (*npmPackageJSON, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	p := &npmPackageJSON{}
+	if err = json.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// npmPackageName is the name of an npm package, "name" or "@scope/name".
+// A scope is part of the package name, it is never a directory level of its own.
+type npmPackageName struct {
+	Scope string
+	Name  string
+}
+
+// parseNpmPackageName parses "name" and "@scope/name"
+func parseNpmPackageName(s string) (npmPackageName, error) {
+	if !strings.HasPrefix(s, "@") {
+		if len(s) == 0 || strings.Contains(s, "/") {
+			return npmPackageName{}, fmt.Errorf("invalid npm package name %q", s)
+		}
+		return npmPackageName{Name: s}, nil
+	}
+	parts := strings.Split(s[1:], "/")
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return npmPackageName{}, fmt.Errorf("invalid scoped npm package name %q", s)
+	}
+	return npmPackageName{Scope: parts[0], Name: parts[1]}, nil
+}
+
+func (n npmPackageName) String() string {
+	if len(n.Scope) == 0 {
+		return n.Name
+	}
+	return "@" + n.Scope + "/" + n.Name
+}
+
+// npmPackageNameFromPath returns the name of the package installed at a node_modules path
+func npmPackageNameFromPath(installPath string) (npmPackageName, error) {
+	dirs := strings.Split(installPath, "/")
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if dirs[i] == nodeModules {
+			dirs = dirs[i+1:]
+			break
+		}
+	}
+	return parseNpmPackageName(strings.Join(dirs, "/"))
+}
+
+// npmPackage is an installed npm package
+type npmPackage struct {
+	Name    npmPackageName
+	Version string
+	// Path is the package directory in the collected sourceFS
+	Path string
+	// Group is the dependency group the package came from
+	Group string
+	// Workspace is the label of the workspace using the package, empty for the root project
+	Workspace string
+}
+
+// ID returns the "name@version" identifier of the package
+func (p npmPackage) ID() string {
+	return npmPackageID(p.Name.String(), p.Version)
+}
+
+// readInstalledPackageJSON returns the package.json of an installed package, nil if it has none
+func readInstalledPackageJSON(fsys sourceFS, p npmPackage) *npmPackageJSON {
+	data, err := fsys.ReadFile(path.Join(p.Path, npmPackageFile))
+	if err != nil {
+		return nil
+	}
+	packageJSON := npmPackageJSON{}
+	if err = json.Unmarshal(data, &packageJSON); err != nil {
+		log.Printf("Failed parsing %s of %s: %s\n", npmPackageFile, p.Path, err)
+		return nil
+	}
+	return &packageJSON
+}
+
+// dependency searches the license in the package directory only. A package never
+// shares the license of its scope directory, nor of the package it is nested in.
+// manualLicense.json keys may be the install path, "name@version" or the name.
+func (p npmPackage) dependency() dependency {
+	keys := []string{p.Path}
+	candidates := []string{p.Name.String()}
+	if len(p.Version) > 0 {
+		candidates = []string{p.ID(), p.Name.String()}
+	}
+	for _, key := range candidates {
+		if !InStringSlice(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return dependency{Name: p.Path, PackageName: p.Name.String(), Version: p.Version, Path: p.Path, LicenseDirs: []string{p.Path}, ManualKeys: keys, Group: p.Group, Workspace: p.Workspace}
+}
+
+// readPackageJSONDependencies returns the direct dependencies of every workspace,
+// except for the workspaces themselves
+func readPackageJSONDependencies(workspaces npmWorkspaces) ([]npmPackage, error) {
+	//Get the package list
+	var packages []npmPackage
+	for _, w := range workspaces {
+		seen := map[string]struct{}{}
+		for _, group := range npmGroups {
+			for name := range w.Package.groupDependencies(group) {
+				if _, ok := seen[name]; ok || workspaces.byName(name) != nil {
+					continue
+				}
+				seen[name] = struct{}{}
+				packageName, err := parseNpmPackageName(name)
+				if err != nil {
+					return nil, err
+				}
+				packages = append(packages, npmPackage{Name: packageName, Path: name, Group: group, Workspace: w.label()})
+			}
+		}
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Path < packages[j].Path })
+	return packages, nil
+}
+
+// npmPackageID returns the "name@version" identifier of a package
+func npmPackageID(name, version string) string {
+	return name + "@" + version
+}
+
+// scanNodeModules maps the "name@version" of every package installed in the given
+// node_modules directories, nested ones included, to its install paths
+func scanNodeModules(fsys sourceFS, dirs ...string) map[string][]string {
+	installed := map[string][]string{}
+	var walk func(rel string)
+	visit := func(rel string) {
+		data, err := fsys.ReadFile(path.Join(rel, npmPackageFile))
+		if err != nil {
+			return
+		}
+		p := npmPackageJSON{}
+		if json.Unmarshal(data, &p) != nil {
+			return
+		}
+		id := npmPackageID(p.Name, p.Version)
+		installed[id] = append(installed[id], rel)
+		walk(path.Join(rel, nodeModules))
+	}
+	walk = func(rel string) {
+		entries, err := fsys.ReadDir(rel)
+		if err != nil {
+			return
+		}
+		for _, entry := range dirEntryNames(entries) {
+			// skip .bin, .cache, the pnpm store etc.
+			if strings.HasPrefix(entry, ".") {
+				continue
+			}
+			if !strings.HasPrefix(entry, "@") {
+				visit(path.Join(rel, entry))
+				continue
+			}
+			scoped, _ := fsys.ReadDir(path.Join(rel, entry))
+			for _, name := range dirEntryNames(scoped) {
+				visit(path.Join(rel, entry, name))
+			}
+		}
+	}
+	for _, dir := range dirs {
+		walk(dir)
+	}
+	return installed
+}
+
+// workspaceNodeModules returns the node_modules directories of a mountFS built by npmSourceFS
+func workspaceNodeModules(fsys mountFS) []string {
+	var dirs []string
+	for mount := range fsys {
+		dirs = append(dirs, mount)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// installedFor keeps the install paths visible from a workspace: the root
+// node_modules and its own, not the node_modules of other workspaces
+func installedFor(paths []string, w npmWorkspace, workspaces npmWorkspaces) []string {
+	var res []string
+	for _, installPath := range paths {
+		visible := true
+		for _, other := range workspaces {
+			if len(other.Dir) > 0 && other.Dir != w.Dir && strings.HasPrefix(installPath, other.Dir+"/"+nodeModules+"/") {
+				visible = false
+				break
+			}
+		}
+		if visible {
+			res = append(res, installPath)
+		}
+	}
+	return res
+}
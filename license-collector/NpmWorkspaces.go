@@ -0,0 +1,217 @@
+package licensecollector
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const pnpmWorkspaceFile = "pnpm-workspace.yaml"
+
+// npmWorkspace is a first party package of a monorepo, or the root project itself
+type npmWorkspace struct {
+	// Dir is the slash separated workspace directory relative to the project, empty for the root project
+	Dir     string
+	Package *npmPackageJSON
+}
+
+// label names the workspace in the report. The root project has no label, its
+// packages go to the main notice.
+func (w npmWorkspace) label() string {
+	if len(w.Dir) == 0 {
+		return ""
+	}
+	if len(w.Package.Name) > 0 {
+		return w.Package.Name
+	}
+	return w.Dir
+}
+
+// npmWorkspaces is the root project followed by its workspaces
+type npmWorkspaces []npmWorkspace
+
+// byName finds a workspace by package name
+func (ws npmWorkspaces) byName(name string) *npmWorkspace {
+	for i := range ws {
+		if len(ws[i].Package.Name) > 0 && ws[i].Package.Name == name {
+			return &ws[i]
+		}
+	}
+	return nil
+}
+
+// byDir finds a workspace by directory
+func (ws npmWorkspaces) byDir(dir string) *npmWorkspace {
+	dir = path.Clean(dir)
+	if dir == "." {
+		dir = ""
+	}
+	for i := range ws {
+		if ws[i].Dir == dir {
+			return &ws[i]
+		}
+	}
+	return nil
+}
+
+// workspacePatterns returns the "workspaces" globs of package.json, either a list
+// or the {"packages": [...]} form of yarn
+func (p *npmPackageJSON) workspacePatterns() []string {
+	var patterns []string
+	if json.Unmarshal(p.Workspaces, &patterns) == nil {
+		return patterns
+	}
+	var obj struct {
+		Packages []string `json:"packages"`
+	}
+	_ = json.Unmarshal(p.Workspaces, &obj)
+	return obj.Packages
+}
+
+// findNpmWorkspaces returns the root project and the workspaces declared in
+// package.json or pnpm-workspace.yaml
+func findNpmWorkspaces(projectDir string) (npmWorkspaces, error) {
+	root, err := readPackageJSON(filepath.Join(projectDir, npmPackageFile))
+	if err != nil {
+		return nil, err
+	}
+	patterns := root.workspacePatterns()
+	if data, err := ioutil.ReadFile(filepath.Join(projectDir, pnpmWorkspaceFile)); err == nil {
+		tree, err := parseLockfile(data)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range tree.child("packages").children() {
+			patterns = append(patterns, c.Value)
+		}
+	}
+
+	dirs := map[string]struct{}{}
+	for _, pattern := range patterns {
+		exclude := strings.HasPrefix(pattern, "!")
+		matches, err := globWorkspaces(projectDir, strings.TrimPrefix(pattern, "!"))
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range matches {
+			if exclude {
+				delete(dirs, dir)
+			} else {
+				dirs[dir] = struct{}{}
+			}
+		}
+	}
+	sorted := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sorted = append(sorted, dir)
+	}
+	sort.Strings(sorted)
+
+	workspaces := npmWorkspaces{{Package: root}}
+	for _, dir := range sorted {
+		p, err := readPackageJSON(filepath.Join(projectDir, filepath.FromSlash(dir), npmPackageFile))
+		if err != nil {
+			continue
+		}
+		workspaces = append(workspaces, npmWorkspace{Dir: dir, Package: p})
+	}
+	if len(workspaces) > 1 {
+		log.Printf("found %d workspaces\n", len(workspaces)-1)
+	}
+	return workspaces, nil
+}
+
+// globWorkspaces expands a workspace pattern to the directories holding a package.json.
+// "**" matches any number of directories, node_modules excluded.
+func globWorkspaces(projectDir string, pattern string) ([]string, error) {
+	pattern = strings.TrimSuffix(path.Clean(pattern), "/"+npmPackageFile)
+	var candidates []string
+	if i := strings.Index(pattern, "**"); i >= 0 {
+		base := strings.TrimSuffix(pattern[:i], "/")
+		err := filepath.Walk(filepath.Join(projectDir, filepath.FromSlash(base)), func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if info.IsDir() && info.Name() == nodeModules {
+				return filepath.SkipDir
+			}
+			if info.IsDir() {
+				candidates = append(candidates, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		matches, err := filepath.Glob(filepath.Join(projectDir, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, err
+		}
+		candidates = matches
+	}
+	var res []string
+	for _, candidate := range candidates {
+		if _, err := os.Stat(filepath.Join(candidate, npmPackageFile)); err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(projectDir, candidate)
+		if err != nil || rel == "." {
+			continue
+		}
+		res = append(res, filepath.ToSlash(rel))
+	}
+	return res, nil
+}
+
+// npmSourceFS mounts the root node_modules, and the node_modules of every workspace
+// under "<workspace dir>/node_modules" for the packages that were not hoisted
+func npmSourceFS(projectDir string, nodeModulesDir string, workspaces npmWorkspaces) mountFS {
+	fsys := mountFS{"": dirFS(nodeModulesDir)}
+	for _, w := range workspaces {
+		if len(w.Dir) == 0 {
+			continue
+		}
+		dir := filepath.Join(projectDir, filepath.FromSlash(w.Dir), nodeModules)
+		if _, err := os.Stat(dir); err == nil {
+			fsys[path.Join(w.Dir, nodeModules)] = dirFS(dir)
+		}
+	}
+	return fsys
+}
+
+// mergeWorkspacePackages unions the packages of all workspaces for a combined notice.
+// A package used by several workspaces keeps its first dependency group.
+func mergeWorkspacePackages(packages []npmPackage) []npmPackage {
+	index := map[string]int{}
+	var res []npmPackage
+	for _, p := range packages {
+		p.Workspace = ""
+		i, ok := index[p.Path]
+		if !ok {
+			index[p.Path] = len(res)
+			res = append(res, p)
+			continue
+		}
+		if groupOrder(p.Group) < groupOrder(res[i].Group) {
+			res[i].Group = p.Group
+		}
+	}
+	return res
+}
+
+// noticeFileName names the notice of an npm workspace or a go binary after the
+// combined notice, "THIRD_PARTY_LICENSE" => "THIRD_PARTY_LICENSE-my-org-web"
+func noticeFileName(fileName string, notice string) string {
+	if len(notice) == 0 {
+		return fileName
+	}
+	slug := strings.Trim(strings.NewReplacer("@", "", "/", "-", "\\", "-", ".", "-").Replace(notice), "-")
+	ext := filepath.Ext(fileName)
+	return strings.TrimSuffix(fileName, ext) + "-" + slug + ext
+}
@@ -0,0 +1,78 @@
+package licensecollector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindNpmWorkspaces(t *testing.T) {
+	packages := map[string]string{
+		"packages/web/package.json":     `{"name": "web"}`,
+		"packages/lib/package.json":     `{"name": "lib"}`,
+		"packages/private/package.json": `{"name": "private"}`,
+		"packages/docs/README.md":       "no package.json",
+		"tools/a/b/package.json":        `{"name": "b"}`,
+	}
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name:  "package.json list",
+			files: map[string]string{"package.json": `{"workspaces": ["packages/*", "!packages/private"]}`},
+			want:  []string{"", "packages/lib", "packages/web"},
+		},
+		{
+			name:  "yarn packages object",
+			files: map[string]string{"package.json": `{"workspaces": {"packages": ["packages/web", "tools/**"]}}`},
+			want:  []string{"", "packages/web", "tools/a/b"},
+		},
+		{
+			name:  "pnpm-workspace.yaml",
+			files: map[string]string{"package.json": `{}`, pnpmWorkspaceFile: "packages:\n  - 'packages/*'\n  - '!packages/private'\n"},
+			want:  []string{"", "packages/lib", "packages/web"},
+		},
+		{
+			name:  "pnpm-workspace.yaml sequence at the key indentation",
+			files: map[string]string{"package.json": `{}`, pnpmWorkspaceFile: "packages:\n- 'packages/*'\n- '!packages/private'\n"},
+			want:  []string{"", "packages/lib", "packages/web"},
+		},
+		{
+			name:  "no workspaces",
+			files: map[string]string{"package.json": `{}`},
+			want:  []string{""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, packages)
+			writeFiles(t, dir, tt.files)
+			workspaces, err := findNpmWorkspaces(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, w := range workspaces {
+				got = append(got, w.Dir)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findNpmWorkspaces() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoticeFileName(t *testing.T) {
+	tests := []struct{ fileName, notice, want string }{
+		{"THIRD_PARTY_LICENSE", "", "THIRD_PARTY_LICENSE"},
+		{"THIRD_PARTY_LICENSE", "@my-org/web", "THIRD_PARTY_LICENSE-my-org-web"},
+		{"out/licenses.json", "web", "out/licenses-web.json"},
+	}
+	for _, tt := range tests {
+		if got := noticeFileName(tt.fileName, tt.notice); got != tt.want {
+			t.Errorf("noticeFileName(%s, %s) = %s, want %s", tt.fileName, tt.notice, got, tt.want)
+		}
+	}
+}
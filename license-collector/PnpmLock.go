@@ -0,0 +1,282 @@
+package licensecollector
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const pnpmLockFile = "pnpm-lock.yaml"
+const pnpmVirtualStore = ".pnpm"
+
+// pnpmLock is a parsed pnpm-lock.yaml. Lockfile versions 5 and 6 keep the package
+// dependencies in the packages section, version 9 moved them to snapshots.
+type pnpmLock struct {
+	root    *lockNode
+	entries map[string][]*lockNode
+}
+
+func readPnpmLock(fileName string) (*pnpmLock, error) {
+	log.Println("Processing pnpm lock file: ", fileName)
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parseLockfile(data)
+	if err != nil {
+		return nil, err
+	}
+	lock := &pnpmLock{root: root, entries: map[string][]*lockNode{}}
+	for _, section := range []string{"packages", "snapshots"} {
+		for _, entry := range root.child(section).children() {
+			lock.entries[entry.Key] = append(lock.entries[entry.Key], entry)
+		}
+	}
+	return lock, nil
+}
+
+// importerDependencies returns the direct dependencies of a group of a workspace,
+// as name to version reference
+func (l *pnpmLock) importerDependencies(dir string, group string) map[string]string {
+	if len(dir) == 0 {
+		dir = "."
+	}
+	importer := l.root.child("importers").child(dir)
+	if importer == nil && dir == "." {
+		// lockfile without workspaces
+		importer = l.root
+	}
+	res := map[string]string{}
+	for _, dep := range importer.child(group).children() {
+		version := dep.Value
+		if len(version) == 0 {
+			// lockfile version 6 and above: {specifier, version}
+			version = dep.childValue("version")
+		}
+		res[dep.Key] = version
+	}
+	return res
+}
+
+// lookup finds the lockfile entries of a dependency version reference, in the key
+// formats of all lockfile versions: "/name/1.0.0", "/name@1.0.0" and "name@1.0.0"
+func (l *pnpmLock) lookup(name, reference string) (string, []*lockNode) {
+	candidates := []string{"/" + name + "/" + reference, "/" + name + "@" + reference, name + "@" + reference}
+	// aliases reference another package: "/other/1.0.0" or "other@1.0.0"
+	candidates = append(candidates, reference, "/"+reference)
+	for _, key := range candidates {
+		if entries, ok := l.entries[key]; ok {
+			return key, entries
+		}
+	}
+	return "", nil
+}
+
+// parsePnpmPackageKey returns the name and version of a packages key, "/name/1.0.0_peer@1.0.0"
+// (lockfile version 5), "/name@1.0.0(peer@1.0.0)" (version 6) or "name@1.0.0(peer@1.0.0)"
+// (version 9), without the peer dependencies suffix. The name may be scoped.
+func parsePnpmPackageKey(key string) (npmPackageName, string, error) {
+	trimmed := strings.TrimPrefix(key, "/")
+	if i := strings.Index(trimmed, "("); i >= 0 {
+		trimmed = trimmed[:i]
+	}
+	// the version separator is searched for after the scope
+	nameStart := 0
+	if strings.HasPrefix(trimmed, "@") {
+		nameStart = strings.Index(trimmed, "/") + 1
+	}
+	i := strings.IndexAny(trimmed[nameStart:], "@/")
+	if i < 0 {
+		return npmPackageName{}, "", fmt.Errorf("invalid %s package key %q", pnpmLockFile, key)
+	}
+	name, version := trimmed[:nameStart+i], trimmed[nameStart+i+1:]
+	if i := strings.Index(version, "_"); i >= 0 {
+		version = version[:i]
+	}
+	packageName, err := parseNpmPackageName(name)
+	if err != nil || len(version) == 0 {
+		return npmPackageName{}, "", fmt.Errorf("invalid %s package key %q", pnpmLockFile, key)
+	}
+	return packageName, version, nil
+}
+
+// pnpmDependency is a dependency of a workspace or package as the lockfile records it,
+// name and version reference, before it is looked up in the packages section
+type pnpmDependency struct {
+	// from is the workspace directory of a direct dependency, to resolve "link:" references
+	from            string
+	name, reference string
+	optional        bool
+}
+
+// resolve walks the importer of a workspace through the packages and snapshots sections.
+// A "link:" reference is a local workspace: its own importer is walked instead of being
+// reported. Every package goes to the group it was first found from, and optional maps its
+// "name@version" to whether only optional dependencies lead to it.
+func (l *pnpmLock) resolve(w npmWorkspace) (packages []npmPackage, optional map[string]bool, err error) {
+	optional = map[string]bool{}
+	seen := map[string]struct{}{}
+	seenWorkspaces := map[string]struct{}{path.Clean("./" + w.Dir): {}}
+	for _, group := range npmGroups {
+		var queue []pnpmDependency
+		for name, reference := range l.importerDependencies(w.Dir, group) {
+			queue = append(queue, pnpmDependency{from: w.Dir, name: name, reference: reference, optional: group == npmOptionalDependencies})
+		}
+		for len(queue) > 0 {
+			dep := queue[0]
+			queue = queue[1:]
+			if strings.HasPrefix(dep.reference, "link:") {
+				// local packages are not third party packages, but workspaces have dependencies
+				dir := path.Clean(path.Join("./"+dep.from, strings.TrimPrefix(dep.reference, "link:")))
+				if _, ok := seenWorkspaces[dir]; ok {
+					continue
+				}
+				seenWorkspaces[dir] = struct{}{}
+				for _, depGroup := range []string{npmDependencies, npmOptionalDependencies} {
+					for name, reference := range l.importerDependencies(dir, depGroup) {
+						queue = append(queue, pnpmDependency{from: dir, name: name, reference: reference, optional: dep.optional || depGroup == npmOptionalDependencies})
+					}
+				}
+				continue
+			}
+			key, entries := l.lookup(dep.name, dep.reference)
+			if entries == nil {
+				log.Printf("%s %s is not in %s\n", dep.name, dep.reference, pnpmLockFile)
+				continue
+			}
+			packageName, version, err := parsePnpmPackageKey(key)
+			if err != nil {
+				return nil, nil, err
+			}
+			p := npmPackage{Name: packageName, Version: version, Group: group, Workspace: w.label()}
+			if o, ok := optional[p.ID()]; ok {
+				optional[p.ID()] = o && dep.optional
+			} else {
+				optional[p.ID()] = dep.optional
+				packages = append(packages, p)
+			}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			for _, entry := range entries {
+				isOptional := dep.optional || entry.childValue("optional") == "true"
+				for _, depGroup := range []string{npmDependencies, npmOptionalDependencies} {
+					for _, c := range entry.child(depGroup).children() {
+						queue = append(queue, pnpmDependency{name: c.Key, reference: c.Value, optional: isOptional || depGroup == npmOptionalDependencies})
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].ID() < packages[j].ID() })
+	return packages, optional, nil
+}
+
+// scanPnpmStore maps the "name@version" of every package in the pnpm virtual store
+// to its real directory, "<store>/<name>@<version>/node_modules/<name>". The other
+// entries of the store node_modules directories are symlinks to dependencies.
+func scanPnpmStore(storeDir string) map[string]string {
+	res := map[string]string{}
+	entries, err := dirFS(storeDir).ReadDir("")
+	if err != nil {
+		return res
+	}
+	for _, entry := range dirEntryNames(entries) {
+		rel := path.Join(entry, nodeModules)
+		names, err := dirFS(storeDir).ReadDir(rel)
+		if err != nil {
+			continue
+		}
+		var candidates []string
+		for _, name := range dirEntryNames(names) {
+			if !strings.HasPrefix(name, "@") {
+				candidates = append(candidates, path.Join(rel, name))
+				continue
+			}
+			scoped, _ := dirFS(storeDir).ReadDir(path.Join(rel, name))
+			for _, s := range dirEntryNames(scoped) {
+				candidates = append(candidates, path.Join(rel, name, s))
+			}
+		}
+		for _, candidate := range candidates {
+			dir := filepath.Join(storeDir, filepath.FromSlash(candidate))
+			info, err := os.Lstat(dir)
+			if err != nil || info.Mode()&os.ModeSymlink != 0 {
+				continue
+			}
+			p, err := readPackageJSON(filepath.Join(dir, npmPackageFile))
+			if err != nil {
+				continue
+			}
+			id := npmPackageID(p.Name, p.Version)
+			if _, ok := res[id]; !ok {
+				res[id] = dir
+			}
+		}
+	}
+	return res
+}
+
+// collectPnpmPackages resolves the packages of every workspace of a pnpm project to
+// their directories in the virtual store, and mounts each of them as "name@version"
+func collectPnpmPackages(fileName string, tmpNpmDir string, nodeModulesDir string, workspaces npmWorkspaces) (sourceFS, []npmPackage, error) {
+	lock, err := readPnpmLock(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	storeDir := filepath.Join(nodeModulesDir, pnpmVirtualStore)
+	var packages []npmPackage
+	if _, err := os.Stat(storeDir); err != nil {
+		// node-linker=hoisted installs a flat node_modules
+		log.Printf("no %s directory found, using %s\n", pnpmVirtualStore, nodeModules)
+		fsys := npmSourceFS(tmpNpmDir, nodeModulesDir, workspaces)
+		installed := scanNodeModules(fsys, workspaceNodeModules(fsys)...)
+		for _, w := range workspaces {
+			resolved, optional, err := lock.resolve(w)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, p := range resolved {
+				paths := installedFor(installed[p.ID()], w, workspaces)
+				if len(paths) == 0 && !optional[p.ID()] {
+					paths = []string{p.Name.String()}
+				}
+				for _, installPath := range paths {
+					p.Path = installPath
+					packages = append(packages, p)
+				}
+			}
+		}
+		return fsys, packages, nil
+	}
+
+	store := scanPnpmStore(storeDir)
+	fsys := mountFS{}
+	for _, w := range workspaces {
+		resolved, optional, err := lock.resolve(w)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, p := range resolved {
+			dir, ok := store[p.ID()]
+			if !ok {
+				if optional[p.ID()] {
+					continue
+				}
+				log.Printf("%s is not in %s\n", p.ID(), storeDir)
+			} else {
+				fsys[p.ID()] = dirFS(dir)
+			}
+			p.Path = p.ID()
+			packages = append(packages, p)
+		}
+	}
+	return fsys, packages, nil
+}
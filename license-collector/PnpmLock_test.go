@@ -0,0 +1,105 @@
+package licensecollector
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParsePnpmPackageKey(t *testing.T) {
+	tests := []struct {
+		key         string
+		wantName    string
+		wantVersion string
+		wantErr     bool
+	}{
+		{key: "/lodash/4.17.21", wantName: "lodash", wantVersion: "4.17.21"},
+		{key: "/@babel/core/7.12.3", wantName: "@babel/core", wantVersion: "7.12.3"},
+		{key: "/styled-components/5.3.0_react-dom@17.0.2+react@17.0.2", wantName: "styled-components", wantVersion: "5.3.0"},
+		{key: "/@emotion/react/11.0.0_react@17.0.2", wantName: "@emotion/react", wantVersion: "11.0.0"},
+		{key: "/lodash_fork/1.0.0", wantName: "lodash_fork", wantVersion: "1.0.0"},
+		{key: "/lodash@4.17.21", wantName: "lodash", wantVersion: "4.17.21"},
+		{key: "/@emotion/react@11.0.0(react@17.0.2)", wantName: "@emotion/react", wantVersion: "11.0.0"},
+		{key: "styled-components@5.3.0(react-dom@17.0.2)(react@17.0.2)", wantName: "styled-components", wantVersion: "5.3.0"},
+		{key: "@emotion/react@11.0.0", wantName: "@emotion/react", wantVersion: "11.0.0"},
+		{key: "/lodash", wantErr: true},
+		{key: "/@emotion@11.0.0", wantErr: true},
+		{key: "/lodash/", wantErr: true},
+		{key: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		name, version, err := parsePnpmPackageKey(tt.key)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsePnpmPackageKey(%q) error = %v, wantErr %v", tt.key, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (name.String() != tt.wantName || version != tt.wantVersion) {
+			t.Errorf("parsePnpmPackageKey(%q) = %s, %s, want %s, %s", tt.key, name, version, tt.wantName, tt.wantVersion)
+		}
+	}
+}
+
+func TestPnpmLockResolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		lock    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "lockfile v5",
+			lock: "lockfileVersion: 5.4\n\nspecifiers:\n  react: ^17.0.2\n  styled-components: ^5.3.0\n\n" +
+				"dependencies:\n  react: 17.0.2\n  styled-components: 5.3.0_react@17.0.2\n\n" +
+				"devDependencies:\n  '@emotion/react': 11.0.0_react@17.0.2\n\n" +
+				"packages:\n\n  /react/17.0.2:\n    resolution: {integrity: sha512-a}\n\n" +
+				"  /styled-components/5.3.0_react@17.0.2:\n    resolution: {integrity: sha512-b}\n    dependencies:\n      react: 17.0.2\n\n" +
+				"  /@emotion/react/11.0.0_react@17.0.2:\n    resolution: {integrity: sha512-c}\n    dev: true\n",
+			want: []string{"@emotion/react@11.0.0 devDependencies", "react@17.0.2 dependencies", "styled-components@5.3.0 dependencies"},
+		},
+		{
+			name: "lockfile v6",
+			lock: "lockfileVersion: '6.0'\n\ndependencies:\n  styled-components:\n    specifier: ^5.3.0\n    version: 5.3.0(react@17.0.2)\n\n" +
+				"optionalDependencies:\n  fsevents:\n    specifier: ^2.0.0\n    version: 2.3.2\n\n" +
+				"packages:\n\n  /react@17.0.2:\n    resolution: {integrity: sha512-a}\n\n" +
+				"  /styled-components@5.3.0(react@17.0.2):\n    resolution: {integrity: sha512-b}\n    dependencies:\n      react: 17.0.2\n\n" +
+				"  /fsevents@2.3.2:\n    resolution: {integrity: sha512-c}\n    optional: true\n",
+			want: []string{"fsevents@2.3.2 optionalDependencies", "react@17.0.2 dependencies", "styled-components@5.3.0 dependencies"},
+		},
+		{
+			name: "lockfile v9",
+			lock: "lockfileVersion: '9.0'\n\nimporters:\n\n  .:\n    dependencies:\n      '@emotion/react':\n        specifier: ^11.0.0\n        version: 11.0.0(react@17.0.2)\n\n" +
+				"packages:\n\n  '@emotion/react@11.0.0':\n    resolution: {integrity: sha512-a}\n\n  react@17.0.2:\n    resolution: {integrity: sha512-b}\n\n" +
+				"snapshots:\n\n  '@emotion/react@11.0.0(react@17.0.2)':\n    dependencies:\n      react: 17.0.2\n\n  react@17.0.2: {}\n",
+			want: []string{"@emotion/react@11.0.0 dependencies", "react@17.0.2 dependencies"},
+		},
+		{
+			name:    "invalid key",
+			lock:    "lockfileVersion: '6.0'\n\ndependencies:\n  '@react':\n    specifier: ^17.0.2\n    version: 17.0.2\n\npackages:\n\n  /@react@17.0.2:\n    resolution: {integrity: sha512-a}\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), pnpmLockFile)
+			if err := ioutil.WriteFile(fileName, []byte(tt.lock), 0644); err != nil {
+				t.Fatal(err)
+			}
+			lock, err := readPnpmLock(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			packages, _, err := lock.resolve(npmWorkspace{Package: &npmPackageJSON{}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, p := range packages {
+				got = append(got, p.ID()+" "+p.Group)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package licensecollector
+
+import (
+	"archive/zip"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// sourceFS gives read access to dependency sources, wherever they are stored.
+// Names are slash separated and relative to the root of the file system.
+type sourceFS interface {
+	// ReadDir returns the entries of a directory, sorted by name
+	ReadDir(name string) ([]dirEntry, error)
+	// ReadFile returns the content of a file
+	ReadFile(name string) ([]byte, error)
+}
+
+// dirEntry is an entry of a sourceFS directory
+type dirEntry struct {
+	Name string
+	// IsDir is false for symlinks, they are not followed
+	IsDir bool
+}
+
+// dirEntryNames returns the names of directory entries
+func dirEntryNames(entries []dirEntry) []string {
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name
+	}
+	return names
+}
+
+// dirFS is a sourceFS rooted at a directory on disk
+type dirFS string
+
+func (d dirFS) ReadDir(name string) ([]dirEntry, error) {
+	entries, err := os.ReadDir(filepath.Join(string(d), filepath.FromSlash(name)))
+	if err != nil {
+		return nil, err
+	}
+	files := make([]dirEntry, len(entries))
+	for i, e := range entries {
+		files[i] = dirEntry{Name: e.Name(), IsDir: e.IsDir()}
+	}
+	return files, nil
+}
+
+func (d dirFS) ReadFile(name string) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(string(d), filepath.FromSlash(name)))
+}
+
+// zipFS is a sourceFS rooted at a directory inside a zip archive.
+// The archive is opened on every call, so many of them can be mounted at once
+// without keeping their files open.
+type zipFS struct {
+	archive string
+	prefix  string
+}
+
+func (z zipFS) ReadDir(name string) ([]dirEntry, error) {
+	r, err := zip.OpenReader(z.archive)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+
+	dir := path.Join(z.prefix, name)
+	if dir != "" && dir != "." {
+		dir += "/"
+	} else {
+		dir = ""
+	}
+	seen := map[string]struct{}{}
+	var files []dirEntry
+	for _, f := range r.File {
+		if !strings.HasPrefix(f.Name, dir) {
+			continue
+		}
+		// the entries of a directory have names below it, "dir/" itself included
+		parts := strings.SplitN(f.Name[len(dir):], "/", 2)
+		if _, ok := seen[parts[0]]; ok || len(parts[0]) == 0 {
+			continue
+		}
+		seen[parts[0]] = struct{}{}
+		files = append(files, dirEntry{Name: parts[0], IsDir: len(parts) == 2})
+	}
+	if len(files) == 0 {
+		return nil, os.ErrNotExist
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, nil
+}
+
+func (z zipFS) ReadFile(name string) ([]byte, error) {
+	r, err := zip.OpenReader(z.archive)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+
+	fullName := path.Join(z.prefix, name)
+	for _, f := range r.File {
+		if f.Name != fullName {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = rc.Close() }()
+		return ioutil.ReadAll(rc)
+	}
+	return nil, os.ErrNotExist
+}
+
+// mountFS joins several sourceFS under virtual directories, e.g. every module
+// of the module cache under its module path
+type mountFS map[string]sourceFS
+
+// resolve finds the mount holding name, preferring the longest mount point.
+// A mount at "" holds every name no other mount holds.
+func (m mountFS) resolve(name string) (sourceFS, string, error) {
+	name = path.Clean(name)
+	if name == "." {
+		name = ""
+	}
+	best := ""
+	found := false
+	for mount := range m {
+		if (len(mount) == 0 || name == mount || strings.HasPrefix(name, mount+"/")) && len(mount) >= len(best) {
+			best = mount
+			found = true
+		}
+	}
+	if !found {
+		return nil, "", os.ErrNotExist
+	}
+	rest := strings.TrimPrefix(strings.TrimPrefix(name, best), "/")
+	if len(rest) == 0 {
+		rest = "."
+	}
+	return m[best], rest, nil
+}
+
+func (m mountFS) ReadDir(name string) ([]dirEntry, error) {
+	fsys, rest, err := m.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	if rest == "." {
+		rest = ""
+	}
+	return fsys.ReadDir(rest)
+}
+
+func (m mountFS) ReadFile(name string) ([]byte, error) {
+	fsys, rest, err := m.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	if rest == "." {
+		return nil, errors.New("cannot read a directory: " + name)
+	}
+	return fsys.ReadFile(rest)
+}
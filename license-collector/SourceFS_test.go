@@ -0,0 +1,70 @@
+package licensecollector
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles writes files under dir, keyed by their slash separated path
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, data := range files {
+		fileName := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fileName, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSourceFSReadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"pkg/LICENSE":                   "MIT",
+		"pkg/main.go":                   "package main",
+		"pkg/zlib-1.2.11/zlib.h":        "",
+		"pkg/vendor/github.com/LICENSE": "",
+	}
+	writeFiles(t, dir, files)
+	archive := filepath.Join(dir, "pkg.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for name, data := range files {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []dirEntry{{Name: "LICENSE"}, {Name: "main.go"}, {Name: "vendor", IsDir: true}, {Name: "zlib-1.2.11", IsDir: true}}
+	for name, fsys := range map[string]sourceFS{
+		"dirFS":   dirFS(filepath.Join(dir, "pkg")),
+		"zipFS":   zipFS{archive: archive, prefix: "pkg"},
+		"mountFS": mountFS{"m": dirFS(filepath.Join(dir, "pkg"))},
+	} {
+		readDir := ""
+		if name == "mountFS" {
+			readDir = "m"
+		}
+		if got, err := fsys.ReadDir(readDir); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s.ReadDir() = %v, %v, want %v", name, got, err, want)
+		}
+	}
+}
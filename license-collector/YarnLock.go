@@ -0,0 +1,237 @@
+package licensecollector
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const yarnLockFile = "yarn.lock"
+const yarnRCFile = ".yarnrc.yml"
+
+// yarnLock is a parsed yarn v1 or Yarn Berry lockfile, indexed by descriptor ("name@range")
+type yarnLock struct {
+	berry   bool
+	entries map[string]*lockNode
+}
+
+// yarnPackage is a package resolved from a yarn lockfile
+type yarnPackage struct {
+	npmPackage
+	optional bool
+}
+
+func readYarnLock(fileName string) (*yarnLock, error) {
+	log.Println("Processing yarn lock file: ", fileName)
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parseLockfile(data)
+	if err != nil {
+		return nil, err
+	}
+	lock := &yarnLock{entries: map[string]*lockNode{}}
+	for _, entry := range root.Children {
+		if entry.Key == "__metadata" {
+			lock.berry = true
+			continue
+		}
+		for _, descriptor := range splitLockKey(entry.Key) {
+			lock.entries[descriptor] = entry
+		}
+	}
+	return lock, nil
+}
+
+// yarnDependency is a version range of a package, the lockfile entry of "name@range"
+// is the version yarn installed for it
+type yarnDependency struct {
+	name, versionRange string
+	optional           bool
+}
+
+// lookup finds the entry of a dependency. Yarn Berry omits the default "npm:"
+// protocol in dependency ranges but not in descriptors.
+func (l *yarnLock) lookup(name, versionRange string) *lockNode {
+	if entry, ok := l.entries[name+"@"+versionRange]; ok {
+		return entry
+	}
+	if l.berry {
+		return l.entries[name+"@npm:"+versionRange]
+	}
+	return nil
+}
+
+// resolve walks the package.json ranges of a workspace through the lockfile entries.
+// yarn.lock has no entries for workspaces, a range naming another workspace continues
+// with that workspace's package.json. Portals and links (linkType: soft) are skipped.
+func (l *yarnLock) resolve(w npmWorkspace, workspaces npmWorkspaces) []yarnPackage {
+	seen := map[*lockNode]int{}
+	seenWorkspaces := map[string]struct{}{w.Dir: {}}
+	var res []yarnPackage
+	for _, group := range npmGroups {
+		var queue []yarnDependency
+		for name, versionRange := range w.Package.groupDependencies(group) {
+			queue = append(queue, yarnDependency{name: name, versionRange: versionRange, optional: group == npmOptionalDependencies})
+		}
+		for len(queue) > 0 {
+			dep := queue[0]
+			queue = queue[1:]
+			if other := workspaces.byName(dep.name); other != nil {
+				if _, ok := seenWorkspaces[other.Dir]; !ok {
+					seenWorkspaces[other.Dir] = struct{}{}
+					for _, depGroup := range []string{npmDependencies, npmOptionalDependencies} {
+						for name, versionRange := range other.Package.groupDependencies(depGroup) {
+							queue = append(queue, yarnDependency{name: name, versionRange: versionRange, optional: dep.optional || depGroup == npmOptionalDependencies})
+						}
+					}
+				}
+				continue
+			}
+			entry := l.lookup(dep.name, dep.versionRange)
+			if entry == nil {
+				log.Printf("%s@%s is not in %s\n", dep.name, dep.versionRange, yarnLockFile)
+				continue
+			}
+			if i, ok := seen[entry]; ok {
+				// a package required by anyone as non optional is not optional
+				res[i].optional = res[i].optional && dep.optional
+				continue
+			}
+			// portals and links are not third party packages
+			if entry.childValue("linkType") == "soft" {
+				continue
+			}
+			seen[entry] = len(res)
+			name, err := parseNpmPackageName(dep.name)
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			p := npmPackage{Name: name, Version: entry.childValue("version"), Group: group, Workspace: w.label()}
+			res = append(res, yarnPackage{npmPackage: p, optional: dep.optional})
+
+			meta := entry.child("dependenciesMeta")
+			for _, depGroup := range []string{npmDependencies, npmOptionalDependencies} {
+				for _, c := range entry.child(depGroup).children() {
+					optional := dep.optional || depGroup == npmOptionalDependencies ||
+						meta.child(c.Key).childValue("optional") == "true"
+					queue = append(queue, yarnDependency{name: c.Key, versionRange: c.Value, optional: optional})
+				}
+			}
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID() < res[j].ID() })
+	return res
+}
+
+// collectYarnPackages resolves the packages of every workspace of a yarn project.
+// Packages are read from node_modules when it exists, and from the Yarn Berry cache
+// archives for Plug'n'Play installs.
+func collectYarnPackages(fileName string, tmpNpmDir string, nodeModulesDir string, workspaces npmWorkspaces) (sourceFS, []npmPackage, error) {
+	lock, err := readYarnLock(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var packages []npmPackage
+	if _, err := os.Stat(nodeModulesDir); err == nil {
+		fsys := npmSourceFS(tmpNpmDir, nodeModulesDir, workspaces)
+		installed := scanNodeModules(fsys, workspaceNodeModules(fsys)...)
+		for _, w := range workspaces {
+			for _, p := range lock.resolve(w, workspaces) {
+				paths := installedFor(installed[p.ID()], w, workspaces)
+				if len(paths) == 0 && !p.optional {
+					// will be reported as missing
+					paths = []string{p.Name.String()}
+				}
+				for _, installPath := range paths {
+					p.Path = installPath
+					packages = append(packages, p.npmPackage)
+				}
+			}
+		}
+		return fsys, packages, nil
+	}
+	if !lock.berry {
+		return nil, nil, errors.New("no node_modules directory found. make sure you 'yarn install'")
+	}
+
+	log.Println("no node_modules directory found, using the yarn cache")
+	cacheDirs := yarnCacheDirs(tmpNpmDir)
+	fsys := mountFS{}
+	mounted := map[string]string{}
+	for _, w := range workspaces {
+		for _, p := range lock.resolve(w, workspaces) {
+			if mount, ok := mounted[p.ID()]; ok {
+				p.Path = mount
+				packages = append(packages, p.npmPackage)
+				continue
+			}
+			archive := findYarnCacheArchive(cacheDirs, p.npmPackage)
+			if len(archive) == 0 {
+				if p.optional {
+					continue
+				}
+				log.Printf("%s is not in the yarn cache\n", p.ID())
+			}
+			p.Path = p.Name.String()
+			if _, ok := fsys[p.Path]; ok {
+				p.Path = p.ID()
+			}
+			mounted[p.ID()] = p.Path
+			if len(archive) > 0 {
+				fsys[p.Path] = zipFS{archive: archive, prefix: nodeModules + "/" + p.Name.String()}
+			}
+			packages = append(packages, p.npmPackage)
+		}
+	}
+	return fsys, packages, nil
+}
+
+// yarnCacheDirs returns the project cache folder and the global cache folder of Yarn Berry
+func yarnCacheDirs(tmpNpmDir string) []string {
+	projectCache := filepath.Join(tmpNpmDir, ".yarn", "cache")
+	if data, err := ioutil.ReadFile(filepath.Join(tmpNpmDir, yarnRCFile)); err == nil {
+		if rc, err := parseLockfile(data); err == nil {
+			if dir := rc.childValue("cacheFolder"); len(dir) > 0 {
+				projectCache = dir
+				if !filepath.IsAbs(dir) {
+					projectCache = filepath.Join(tmpNpmDir, dir)
+				}
+			}
+		}
+	}
+	globalCache := os.Getenv("YARN_CACHE_FOLDER")
+	if len(globalCache) == 0 {
+		home, _ := os.UserHomeDir()
+		globalCache = filepath.Join(home, ".yarn", "berry", "cache")
+	}
+	return []string{projectCache, globalCache}
+}
+
+// findYarnCacheArchive finds the cache archive of a package. Archives are named after
+// the slugified locator, e.g. "@babel-core-npm-7.12.3-<hash>-<checksum>.zip"
+func findYarnCacheArchive(cacheDirs []string, p npmPackage) string {
+	slug := p.Name.Name + "-npm-" + p.Version + "-"
+	if len(p.Name.Scope) > 0 {
+		slug = "@" + p.Name.Scope + "-" + slug
+	}
+	for _, dir := range cacheDirs {
+		files, err := dirFS(dir).ReadDir("")
+		if err != nil {
+			continue
+		}
+		for _, file := range dirEntryNames(files) {
+			if strings.HasPrefix(file, slug) && strings.HasSuffix(file, ".zip") {
+				return filepath.Join(dir, file)
+			}
+		}
+	}
+	return ""
+}
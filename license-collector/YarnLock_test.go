@@ -0,0 +1,84 @@
+package licensecollector
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestYarnLockResolve(t *testing.T) {
+	root := &npmPackageJSON{
+		Dependencies:         map[string]string{"a": "^1.0.0", "@s/c": "^1.0.0"},
+		OptionalDependencies: map[string]string{"o": "^1.0.0"},
+		DevDependencies:      map[string]string{"d": "^1.0.0"},
+	}
+	tests := []struct {
+		name string
+		lock string
+		want []string
+	}{
+		{
+			name: "yarn v1",
+			lock: "# yarn lockfile v1\n\n" +
+				"a@^1.0.0:\n  version \"1.0.0\"\n  dependencies:\n    b \"^2.0.0\"\n\n" +
+				"\"@s/c@^1.0.0\":\n  version \"1.2.0\"\n\n" +
+				"b@^2.0.0, b@^2.1.0:\n  version \"2.1.0\"\n\n" +
+				"d@^1.0.0:\n  version \"1.0.0\"\n  dependencies:\n    a \"^1.0.0\"\n\n" +
+				"o@^1.0.0:\n  version \"1.0.0\"\n",
+			want: []string{"@s/c@1.2.0 dependencies false", "a@1.0.0 dependencies false", "b@2.1.0 dependencies false", "d@1.0.0 devDependencies false", "o@1.0.0 optionalDependencies true"},
+		},
+		{
+			name: "yarn berry",
+			lock: "__metadata:\n  version: 6\n\n" +
+				"\"a@npm:^1.0.0\":\n  version: 1.0.0\n  dependencies:\n    b: ^2.0.0\n  dependenciesMeta:\n    b:\n      optional: true\n  linkType: hard\n\n" +
+				"\"@s/c@npm:^1.0.0\":\n  version: 1.2.0\n  linkType: hard\n\n" +
+				"\"b@npm:^2.0.0\":\n  version: 2.1.0\n  linkType: hard\n\n" +
+				"\"d@npm:^1.0.0\":\n  version: 1.0.0\n  linkType: hard\n\n" +
+				"\"o@portal:../o\":\n  version: 0.0.0-use.local\n  linkType: soft\n\n" +
+				"\"o@npm:^1.0.0\":\n  version: 0.0.0-use.local\n  linkType: soft\n",
+			want: []string{"@s/c@1.2.0 dependencies false", "a@1.0.0 dependencies false", "b@2.1.0 dependencies true", "d@1.0.0 devDependencies false"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), yarnLockFile)
+			if err := ioutil.WriteFile(fileName, []byte(tt.lock), 0644); err != nil {
+				t.Fatal(err)
+			}
+			lock, err := readYarnLock(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, p := range lock.resolve(npmWorkspace{Package: root}, npmWorkspaces{{Package: root}}) {
+				optional := "false"
+				if p.optional {
+					optional = "true"
+				}
+				got = append(got, p.ID()+" "+p.Group+" "+optional)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestYarnLockResolveWorkspaces(t *testing.T) {
+	lib := &npmPackageJSON{Name: "lib", Dependencies: map[string]string{"b": "^2.0.0"}}
+	web := &npmPackageJSON{Name: "web", Dependencies: map[string]string{"lib": "*"}}
+	workspaces := npmWorkspaces{{Package: &npmPackageJSON{}}, {Dir: "packages/lib", Package: lib}, {Dir: "packages/web", Package: web}}
+	fileName := filepath.Join(t.TempDir(), yarnLockFile)
+	if err := ioutil.WriteFile(fileName, []byte("b@^2.0.0:\n  version \"2.1.0\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lock, err := readYarnLock(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packages := lock.resolve(workspaces[2], workspaces)
+	if len(packages) != 1 || packages[0].ID() != "b@2.1.0" || packages[0].Workspace != "web" {
+		t.Errorf("resolve() = %+v, want b@2.1.0 of web", packages)
+	}
+}
@@ -4,21 +4,52 @@ import (
 	"flag"
 	"log"
 	"os"
+	"strings"
 
 	licensecollector "github.com/aviadl/thirdPartyLicenseCollector/license-collector"
 )
 
 func main() {
 	tmpGoDir := flag.String("go-project", "", "project directory")
+	goBinary := flag.String("go-binary", "", "go executable, collects the modules linked into it (optional, -go-project provides the vendor directory)")
+	goPerBinary := flag.Bool("go-per-binary", false, "create a license file per main package of go-project, e.g. cmd/*, with the modules it imports")
+	goTestDeps := flag.String("go-test-deps", licensecollector.GoTestDependenciesExclude, "go modules imported by tests only: exclude, separate (a development only section) or include")
+	goTags := flag.String("go-tags", "", "comma separated build tags used by go-per-binary and go-test-deps")
+	goOS := flag.String("goos", "", "GOOS used by go-per-binary (the current one by default) and go-test-deps (every GOOS by default)")
+	goArch := flag.String("goarch", "", "GOARCH used by go-per-binary (the current one by default) and go-test-deps (every GOARCH by default)")
 	tmpNpmDir := flag.String("npm-project", "", "npm directory")
 	// For some project - the node modules are not in the same directory as the package.json
 	tmpNodeModulesDir := flag.String("npm-node-modules", "", "node_modules directory (optional, leave empty if it is in the same as npm-project)")
+	npmGroups := flag.String("npm-groups", "dependencies", "comma separated npm dependency groups: dependencies, optionalDependencies, peerDependencies, devDependencies")
+	npmWorkspaceNotices := flag.Bool("npm-workspace-notices", false, "create a license file per npm workspace instead of a combined one")
 	out := flag.String("out", licensecollector.LicenseFileName, "output file")
 	format := flag.String("format", licensecollector.DefaultLicenseFileFormat, "output format: text vs json")
+	licensePreference := flag.String("license-preference", "", "comma separated SPDX license identifiers in order of preference, the license a dual licensed package is complied under")
+	reviewThreshold := flag.Float64("review-threshold", 0, "confidence percentage under which a license decision needs review, e.g. 80, failing the collection")
+	deepScan := flag.Bool("deep-scan", false, "report the license files of the components embedded in the subdirectories of the packages, e.g. third_party/")
+	originalTexts := flag.Bool("original-texts", false, "report the license files of the packages as is, copyright lines included, instead of the SPDX license texts")
 	flag.Parse()
 	log.SetFlags(0)
 
-	err := licensecollector.Collect(*tmpGoDir, *tmpNpmDir, *tmpNodeModulesDir, *out, *format)
+	err := licensecollector.CollectWithOptions(licensecollector.Options{
+		ProjectGO:           *tmpGoDir,
+		GoBinary:            *goBinary,
+		GoPerBinary:         *goPerBinary,
+		GoTestDependencies:  *goTestDeps,
+		GoBuildTags:         strings.Split(*goTags, ","),
+		GoOS:                *goOS,
+		GoArch:              *goArch,
+		ProjectNPM:          *tmpNpmDir,
+		ProjectNodeModules:  *tmpNodeModulesDir,
+		NpmGroups:           strings.Split(*npmGroups, ","),
+		NpmWorkspaceNotices: *npmWorkspaceNotices,
+		FileName:            *out,
+		FileFormat:          *format,
+		LicensePreference:   strings.Split(*licensePreference, ","),
+		ReviewThreshold:     *reviewThreshold,
+		DeepScan:            *deepScan,
+		OriginalTexts:       *originalTexts,
+	})
 	if err != nil {
 		log.Println(err)
 		os.Exit(1)
@@ -0,0 +1,122 @@
+// spdxgen generates the SPDX license list of the license collector from a local copy
+// of https://github.com/spdx/license-list-data, no network access needed:
+//
+//	go run ./tools/spdxgen -data <license-list-data dir> -out license-collector/SpdxLicenses.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+type licenseList struct {
+	LicenseListVersion string `json:"licenseListVersion"`
+	Licenses           []struct {
+		LicenseID     string `json:"licenseId"`
+		Name          string `json:"name"`
+		IsOsiApproved bool   `json:"isOsiApproved"`
+		IsDeprecated  bool   `json:"isDeprecatedLicenseId"`
+	} `json:"licenses"`
+}
+
+type licenseDetails struct {
+	LicenseText             string `json:"licenseText"`
+	StandardLicenseTemplate string `json:"standardLicenseTemplate"`
+	IsFsfLibre              bool   `json:"isFsfLibre"`
+}
+
+type exceptionList struct {
+	Exceptions []struct {
+		LicenseExceptionID string `json:"licenseExceptionId"`
+		Name               string `json:"name"`
+		IsDeprecated       bool   `json:"isDeprecatedLicenseId"`
+	} `json:"exceptions"`
+}
+
+type exceptionDetails struct {
+	LicenseExceptionText string `json:"licenseExceptionText"`
+}
+
+func readJSON(fileName string, v interface{}) error {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %s", fileName, err)
+	}
+	return nil
+}
+
+func main() {
+	dataDir := flag.String("data", "", "license-list-data directory, holding json/licenses.json")
+	out := flag.String("out", "SpdxLicenses.go", "generated go file")
+	flag.Parse()
+	log.SetFlags(0)
+	if len(*dataDir) == 0 {
+		log.Fatal("missing -data, the license-list-data directory")
+	}
+	jsonDir := filepath.Join(*dataDir, "json")
+
+	licenses := licenseList{}
+	if err := readJSON(filepath.Join(jsonDir, "licenses.json"), &licenses); err != nil {
+		log.Fatal(err)
+	}
+	sort.Slice(licenses.Licenses, func(i, j int) bool {
+		return licenses.Licenses[i].LicenseID < licenses.Licenses[j].LicenseID
+	})
+	exceptions := exceptionList{}
+	if err := readJSON(filepath.Join(jsonDir, "exceptions.json"), &exceptions); err != nil {
+		log.Fatal(err)
+	}
+	sort.Slice(exceptions.Exceptions, func(i, j int) bool {
+		return exceptions.Exceptions[i].LicenseExceptionID < exceptions.Exceptions[j].LicenseExceptionID
+	})
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by spdxgen from the SPDX license list %s. DO NOT EDIT.\n\n", licenses.LicenseListVersion)
+	b.WriteString("package licensecollector\n\n")
+	fmt.Fprintf(&b, "// spdxLicenseListVersion is the version of the SPDX license list\nconst spdxLicenseListVersion = %q\n\n", licenses.LicenseListVersion)
+	b.WriteString("// spdxLicenses are the licenses of the SPDX license list, by identifier\nvar spdxLicenses = map[string]spdxLicense{\n")
+	for _, l := range licenses.Licenses {
+		details := licenseDetails{}
+		if err := readJSON(filepath.Join(jsonDir, "details", l.LicenseID+".json"), &details); err != nil {
+			log.Fatal(err)
+		}
+		// the template is only kept when it has more than the text, e.g. optional and replaceable parts
+		template := ""
+		if details.StandardLicenseTemplate != details.LicenseText {
+			template = details.StandardLicenseTemplate
+		}
+		fmt.Fprintf(&b, "\t%q: {ID: %q, Name: %q, OSIApproved: %t, FSFLibre: %t, Deprecated: %t, Text: %s, Template: %s},\n",
+			l.LicenseID, l.LicenseID, l.Name, l.IsOsiApproved, details.IsFsfLibre, l.IsDeprecated, strconv.Quote(details.LicenseText), strconv.Quote(template))
+	}
+	b.WriteString("}\n\n")
+	b.WriteString("// spdxExceptions are the license exceptions of the SPDX license list, by identifier\nvar spdxExceptions = map[string]spdxLicense{\n")
+	for _, e := range exceptions.Exceptions {
+		details := exceptionDetails{}
+		if err := readJSON(filepath.Join(jsonDir, "exceptions", e.LicenseExceptionID+".json"), &details); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintf(&b, "\t%q: {ID: %q, Name: %q, Deprecated: %t, Text: %s},\n",
+			e.LicenseExceptionID, e.LicenseExceptionID, e.Name, e.IsDeprecated, strconv.Quote(details.LicenseExceptionText))
+	}
+	b.WriteString("}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("generated %s: %d licenses, %d exceptions\n", *out, len(licenses.Licenses), len(exceptions.Exceptions))
+}